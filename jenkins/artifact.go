@@ -1,13 +1,21 @@
 package gojenkins
 
 import (
+	"bytes"
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path"
+	"strconv"
+	"strings"
 )
 
 // Represents an Artifact
@@ -15,90 +23,389 @@ type Artifact struct {
 	Jenkins  *Jenkins
 	Build    *Build
 	FileName string
-	Path     string
+	// RelativePath is the path of the artifact below the build's artifact
+	// directory, e.g. "linux-amd64/bin/app".
+	RelativePath string
+	Path         string
+}
+
+var (
+	// ErrChecksumMismatch is returned when downloaded data does not match the
+	// fingerprint Jenkins recorded for the artifact.
+	ErrChecksumMismatch = errors.New("artifact checksum does not match its fingerprint")
+	// ErrNoFingerPrint is returned when Jenkins has no fingerprint to verify
+	// the artifact against, e.g. because the job does not record them.
+	ErrNoFingerPrint = errors.New("no fingerprint recorded for artifact")
+)
+
+// Verification holds the checksums of artifact data and whether its MD5
+// matches the fingerprint recorded by Jenkins.
+type Verification struct {
+	MD5    string
+	SHA256 string
+	// Verified is only set when verification was requested and succeeded.
+	Verified    bool
+	FingerPrint *FingerPrintResponse
+}
+
+// DownloadResult describes a finished download. The checksums are empty
+// if the data before DownloadOptions.Offset was not available to hash.
+type DownloadResult struct {
+	Verification
+	// Written is the number of bytes written by this download.
+	Written int64
+}
+
+// DownloadOptions controls how an artifact is streamed.
+type DownloadOptions struct {
+	// Offset is the number of bytes the caller already has. The download
+	// continues from there with an HTTP Range request.
+	Offset int64
+	// MaxResumes is how many times an interrupted transfer is resumed from
+	// where it stopped before the error is returned.
+	MaxResumes int
+	// Progress is called after every chunk with the number of bytes
+	// downloaded so far, including Offset, and the total size or -1 if
+	// Jenkins did not report it.
+	Progress func(downloaded, total int64)
+	// Verify compares the MD5 of the data with the fingerprint Jenkins
+	// recorded for the artifact and fails the download if they differ or
+	// no fingerprint exists.
+	Verify bool
 }
 
 // Get raw byte data of Artifact
 func (a Artifact) GetData() ([]byte, error) {
-	var data string
-	response, err := a.Jenkins.Requester.Get(a.Path, &data, nil)
+	return a.GetDataContext(context.Background())
+}
 
-	if err != nil {
+func (a Artifact) GetDataContext(ctx context.Context) ([]byte, error) {
+	var data bytes.Buffer
+	if _, err := a.DownloadContext(ctx, &data, nil); err != nil {
 		return nil, err
 	}
+	return data.Bytes(), nil
+}
 
-	code := response.StatusCode
-	if code != 200 {
-		Error.Printf("Jenkins responded with StatusCode: %d", code)
-		return nil, errors.New("Could not get File Contents")
+// Stream the artifact into w without holding it in memory.
+// Verify can't be combined with an Offset, as the checksum must cover the
+// whole artifact; use DownloadToFile to resume verified downloads.
+func (a Artifact) Download(w io.Writer, opts *DownloadOptions) (*DownloadResult, error) {
+	return a.DownloadContext(context.Background(), w, opts)
+}
+
+func (a Artifact) DownloadContext(ctx context.Context, w io.Writer, opts *DownloadOptions) (*DownloadResult, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	if opts.Verify && opts.Offset > 0 {
+		return nil, errors.New("can't verify an artifact download resumed from an offset")
+	}
+	h := newArtifactHash()
+	n, err := a.download(ctx, w, h, opts)
+	if err != nil {
+		return nil, err
 	}
-	return []byte(data), nil
+	result := &DownloadResult{Written: n}
+	if opts.Offset == 0 {
+		result.Verification = h.verification()
+	}
+	if opts.Verify {
+		if err := a.verify(ctx, &result.Verification); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
 }
 
-// Save artifact to a specific path, using your own filename.
-func (a Artifact) Save(path string) (bool, error) {
-	data, err := a.GetData()
+// Download the artifact to path. A partial file left by an earlier
+// interrupted call is resumed instead of downloaded again; opts.Offset is
+// ignored. The checksums cover the whole file. A file that fails
+// verification with ErrChecksumMismatch is removed.
+func (a Artifact) DownloadToFile(path string, opts *DownloadOptions) (*DownloadResult, error) {
+	return a.DownloadToFileContext(context.Background(), path, opts)
+}
 
+func (a Artifact) DownloadToFileContext(ctx context.Context, path string, opts *DownloadOptions) (*DownloadResult, error) {
+	var o DownloadOptions
+	if opts != nil {
+		o = *opts
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
 	if err != nil {
-		return false, errors.New("No Data received, not saving file.")
+		return nil, err
+	}
+
+	// Hash what is already on disk, which also moves to the end of the file.
+	h := newArtifactHash()
+	o.Offset, err = io.Copy(h, f)
+	var n int64
+	if err == nil {
+		n, err = a.download(ctx, f, h, &o)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DownloadResult{Verification: h.verification(), Written: n}
+	if o.Verify {
+		if err := a.verify(ctx, &result.Verification); err != nil {
+			if errors.Is(err, ErrChecksumMismatch) {
+				os.Remove(path)
+			}
+			return result, err
+		}
 	}
+	return result, nil
+}
+
+func (a Artifact) download(ctx context.Context, w io.Writer, h io.Writer, opts *DownloadOptions) (int64, error) {
+	offset := opts.Offset
+	var written int64
+	for resumes := 0; ; resumes++ {
+		ar := NewAPIRequest("GET", a.Path, nil)
+		if offset > 0 {
+			ar.SetHeader("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+		var body io.ReadCloser
+		response, err := a.Jenkins.Requester.DoContext(ctx, ar, &body)
+		if offset > 0 && StatusCode(err) == http.StatusRequestedRangeNotSatisfiable &&
+			contentRangeTotal(response.Header.Get("Content-Range")) == offset {
+			// Nothing left to download.
+			if opts.Progress != nil {
+				opts.Progress(offset, offset)
+			}
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
 
-	if _, err = os.Stat(path); err == nil {
-		Warning.Println("Local Copy already exists, Overwriting...")
+		if offset > 0 && response.StatusCode != http.StatusPartialContent {
+			// The range was ignored, skip the part we already have.
+			if _, err := io.CopyN(ioutil.Discard, body, offset); err != nil {
+				body.Close()
+				return written, err
+			}
+		}
+		cw := &downloadWriter{w: io.MultiWriter(w, h), downloaded: offset, total: downloadSize(response, offset), progress: opts.Progress}
+		n, err := io.Copy(cw, body)
+		body.Close()
+		written += n
+		offset += n
+		if err == nil && cw.total >= 0 && offset < cw.total {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil {
+			return written, nil
+		}
+		// Only failures reading from Jenkins are worth resuming.
+		if cw.err != nil || ctx.Err() != nil || resumes >= opts.MaxResumes {
+			return written, err
+		}
+		a.Jenkins.logger().Warn("artifact download interrupted, resuming", "path", a.Path, "offset", offset, "error", err)
 	}
+}
 
-	err = ioutil.WriteFile(path, data, 0644)
-	a.validateDownload(path)
+// downloadWriter reports progress and remembers write errors, which
+// io.Copy returns without telling them apart from read errors.
+type downloadWriter struct {
+	w          io.Writer
+	downloaded int64
+	total      int64
+	progress   func(downloaded, total int64)
+	err        error
+}
 
+func (d *downloadWriter) Write(p []byte) (int, error) {
+	n, err := d.w.Write(p)
+	d.downloaded += int64(n)
+	d.err = err
+	if d.progress != nil && n > 0 {
+		d.progress(d.downloaded, d.total)
+	}
+	return n, err
+}
+
+// downloadSize returns the size of the whole artifact, or -1 if unknown.
+func downloadSize(response *http.Response, offset int64) int64 {
+	if response.StatusCode == http.StatusPartialContent {
+		if total := contentRangeTotal(response.Header.Get("Content-Range")); total >= 0 {
+			return total
+		}
+		if response.ContentLength >= 0 {
+			return offset + response.ContentLength
+		}
+		return -1
+	}
+	return response.ContentLength
+}
+
+// contentRangeTotal parses the complete length from a Content-Range header
+// such as "bytes 100-199/200" or "bytes */200", returning -1 if it is absent.
+func contentRangeTotal(header string) int64 {
+	idx := strings.LastIndex(header, "/")
+	if idx < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(header[idx+1:], 10, 64)
 	if err != nil {
-		return false, err
+		return -1
+	}
+	return total
+}
+
+// Save artifact to a specific path, using your own filename.
+// The download fails with ErrChecksumMismatch if it does not match the
+// fingerprint Jenkins recorded; artifacts without a fingerprint are saved
+// unverified.
+func (a Artifact) Save(path string) (bool, error) {
+	return a.SaveContext(context.Background(), path)
+}
+
+func (a Artifact) SaveContext(ctx context.Context, path string) (bool, error) {
+	if _, err := os.Stat(path); err == nil {
+		a.Jenkins.logger().Warn("local copy already exists, overwriting", "path", path)
+		if err = os.Truncate(path, 0); err != nil {
+			return false, err
+		}
+	}
+
+	result, err := a.DownloadToFileContext(ctx, path, &DownloadOptions{Verify: true})
+	if errors.Is(err, ErrNoFingerPrint) {
+		a.Jenkins.logger().Warn("artifact saved without verification", "path", path, "md5", result.MD5, "sha256", result.SHA256)
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("not saving %s: %w", a.FileName, err)
 	}
 	return true, nil
 }
 
 // Save Artifact to directory using Artifact filename.
+// Use Build.DownloadArtifacts to keep the directory layout of the build.
 func (a Artifact) SaveToDir(dir string) (bool, error) {
+	return a.SaveToDirContext(context.Background(), dir)
+}
+
+func (a Artifact) SaveToDirContext(ctx context.Context, dir string) (bool, error) {
 	if _, err := os.Stat(dir); err != nil {
-		Error.Printf("can't save artifact: directory %s does not exist", dir)
 		return false, fmt.Errorf("can't save artifact: directory %s does not exist", dir)
 	}
-	saved, err := a.Save(path.Join(dir, a.FileName))
-	if err != nil {
-		return saved, nil
-	}
-	return saved, nil
+	return a.SaveContext(ctx, path.Join(dir, a.FileName))
 }
 
-// Compare Remote and local MD5
-func (a Artifact) validateDownload(path string) (bool, error) {
-	localHash := a.getMD5local(path)
+// Compute the checksums of a local copy of the artifact and compare its MD5
+// with the fingerprint recorded by Jenkins.
+func (a Artifact) VerifyFile(path string) (*Verification, error) {
+	return a.VerifyFileContext(context.Background(), path)
+}
 
-	fp := FingerPrint{Jenkins: a.Jenkins, Base: "/fingerprint/", Id: localHash, Raw: new(FingerPrintResponse)}
+func (a Artifact) VerifyFileContext(ctx context.Context, path string) (*Verification, error) {
+	localFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer localFile.Close()
 
-	valid, err := fp.ValidateForBuild(a.FileName, a.Build)
+	h := newArtifactHash()
+	if _, err := io.Copy(h, localFile); err != nil {
+		return nil, err
+	}
+	v := h.verification()
+	if err := a.verify(ctx, &v); err != nil {
+		return &v, err
+	}
+	return &v, nil
+}
 
-	if err != nil {
-		return false, err
+// verify compares v.MD5 with the fingerprints Jenkins recorded for the
+// artifact. The fingerprints listed on the build are checked first, as a
+// lookup by hash can't tell corrupted data from an unrecorded artifact.
+func (a Artifact) verify(ctx context.Context, v *Verification) error {
+	if a.Build != nil {
+		fingerprints, err := a.Build.fingerprints(ctx)
+		if err != nil {
+			return err
+		}
+		recorded := false
+		for i, fp := range fingerprints {
+			if fp.FileName != a.FileName {
+				continue
+			}
+			recorded = true
+			if fp.Hash == v.MD5 {
+				v.Verified = true
+				v.FingerPrint = &fingerprints[i]
+				return nil
+			}
+		}
+		if recorded {
+			return fmt.Errorf("%w: %s has MD5 %s", ErrChecksumMismatch, a.FileName, v.MD5)
+		}
 	}
-	if !valid {
-		return false, errors.New("FingerPrint of the downloaded artifact could not be verified")
+
+	fp := FingerPrint{Jenkins: a.Jenkins, Base: "/fingerprint/", Id: v.MD5, Raw: new(FingerPrintResponse)}
+	if _, err := fp.PollContext(ctx); err != nil {
+		if IsNotFound(err) {
+			return fmt.Errorf("%w: %s with MD5 %s", ErrNoFingerPrint, a.FileName, v.MD5)
+		}
+		return err
 	}
-	return true, nil
+	if fp.Raw.Hash != v.MD5 || fp.Raw.FileName != a.FileName {
+		return fmt.Errorf("%w: %s has MD5 %s, Jenkins knows it as %s", ErrChecksumMismatch, a.FileName, v.MD5, fp.Raw.FileName)
+	}
+	v.Verified = true
+	v.FingerPrint = fp.Raw
+	return nil
 }
 
-// Get Local MD5
-func (a Artifact) getMD5local(path string) string {
-	h := md5.New()
-	localFile, err := os.Open(path)
+// fingerprints returns the fingerprints recorded by the build. Builds
+// polled at depth 1 or less don't include them, so they are fetched then.
+// An empty but non-nil b.Raw.FingerPrint means the build has none.
+func (b *Build) fingerprints(ctx context.Context) ([]FingerPrintResponse, error) {
+	if b.Raw != nil && b.Raw.FingerPrint != nil {
+		return b.Raw.FingerPrint, nil
+	}
+	if b.Jenkins == nil {
+		return nil, nil
+	}
+	var resp struct {
+		FingerPrint []FingerPrintResponse `json:"fingerprint"`
+	}
+	_, err := b.Jenkins.Requester.GetJSONContext(ctx, b.Base, &resp, map[string]string{"tree": "fingerprint[fileName,hash]"})
 	if err != nil {
-		return ""
+		return nil, err
 	}
-	buffer := make([]byte, 2^20)
-	n, err := localFile.Read(buffer)
-	defer localFile.Close()
-	for err == nil {
-		io.WriteString(h, string(buffer[0:n]))
-		n, err = localFile.Read(buffer)
+	return resp.FingerPrint, nil
+}
+
+// artifactHash computes the MD5 used by Jenkins fingerprints and a SHA-256
+// in one pass.
+type artifactHash struct {
+	md5    hash.Hash
+	sha256 hash.Hash
+}
+
+func newArtifactHash() *artifactHash {
+	return &artifactHash{md5: md5.New(), sha256: sha256.New()}
+}
+
+func (h *artifactHash) Write(p []byte) (int, error) {
+	h.md5.Write(p)
+	h.sha256.Write(p)
+	return len(p), nil
+}
+
+func (h *artifactHash) verification() Verification {
+	return Verification{
+		MD5:    hex.EncodeToString(h.md5.Sum(nil)),
+		SHA256: hex.EncodeToString(h.sha256.Sum(nil)),
 	}
-	return fmt.Sprintf("%x", h.Sum(nil))
 }
@@ -0,0 +1,305 @@
+package gojenkins
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Number of parallel downloads used by DownloadArtifacts by default.
+const defaultArtifactConcurrency = 4
+
+// ArtifactsOptions controls Build.DownloadArtifacts.
+type ArtifactsOptions struct {
+	// Include and Exclude are Ant-style globs matched against the relative
+	// path of each artifact, e.g. "linux-*/**/*.tar.gz", where "**" matches
+	// any number of directories. Without Include every artifact is selected.
+	Include []string
+	Exclude []string
+	// Concurrency limits the number of parallel downloads.
+	Concurrency int
+	// Archive fetches all artifacts in a single request from the
+	// *zip*/archive.zip endpoint and extracts the selected ones, which is
+	// faster for builds with many small artifacts.
+	Archive bool
+	// Verify checks every artifact against its fingerprint.
+	Verify bool
+}
+
+// SavedArtifact is an artifact written by Build.DownloadArtifacts.
+type SavedArtifact struct {
+	Artifact Artifact
+	// LocalPath is the file the artifact was written to.
+	LocalPath string
+	Result    *DownloadResult
+}
+
+// Download the artifacts of the build into dir, keeping their relative
+// paths. Existing files are overwritten. On error the artifacts saved so
+// far are returned with it.
+func (b *Build) DownloadArtifacts(dir string, opts *ArtifactsOptions) ([]SavedArtifact, error) {
+	return b.DownloadArtifactsContext(context.Background(), dir, opts)
+}
+
+func (b *Build) DownloadArtifactsContext(ctx context.Context, dir string, opts *ArtifactsOptions) ([]SavedArtifact, error) {
+	if opts == nil {
+		opts = &ArtifactsOptions{}
+	}
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if err := validateGlob(pattern); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Verify && b.Raw.FingerPrint == nil {
+		// Fetch the fingerprints once instead of for every artifact.
+		fingerprints, err := b.fingerprints(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if fingerprints == nil {
+			fingerprints = []FingerPrintResponse{}
+		}
+		b.Raw.FingerPrint = fingerprints
+	}
+	if opts.Archive {
+		return b.extractArtifacts(ctx, dir, opts)
+	}
+
+	var artifacts []Artifact
+	for _, a := range b.GetArtifacts() {
+		if opts.selects(a.RelativePath) {
+			artifacts = append(artifacts, a)
+		}
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultArtifactConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	saved := make([]*SavedArtifact, len(artifacts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	for i, a := range artifacts {
+		wg.Add(1)
+		go func(i int, a Artifact) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			s, err := b.saveArtifact(ctx, dir, a, opts.Verify)
+			if err != nil {
+				// Stop the remaining downloads on the first failure.
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			saved[i] = s
+		}(i, a)
+	}
+	wg.Wait()
+
+	result := make([]SavedArtifact, 0, len(saved))
+	for _, s := range saved {
+		if s != nil {
+			result = append(result, *s)
+		}
+	}
+	if firstErr == nil {
+		// The caller's context may have ended before any download failed.
+		firstErr = ctx.Err()
+	}
+	return result, firstErr
+}
+
+func (b *Build) saveArtifact(ctx context.Context, dir string, a Artifact, verify bool) (*SavedArtifact, error) {
+	localPath := artifactLocalPath(dir, a.RelativePath)
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return nil, err
+	}
+	// Overwrite rather than resume whatever is there.
+	if err := os.Truncate(localPath, 0); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	result, err := a.DownloadToFileContext(ctx, localPath, &DownloadOptions{Verify: verify})
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", a.RelativePath, err)
+	}
+	return &SavedArtifact{Artifact: a, LocalPath: localPath, Result: result}, nil
+}
+
+// Stream all artifacts of the build as one zip archive into w.
+func (b *Build) DownloadArtifactsArchive(w io.Writer) (*DownloadResult, error) {
+	return b.DownloadArtifactsArchiveContext(context.Background(), w)
+}
+
+func (b *Build) DownloadArtifactsArchiveContext(ctx context.Context, w io.Writer) (*DownloadResult, error) {
+	archive := Artifact{Jenkins: b.Jenkins, Build: b, FileName: "archive.zip", Path: b.Base + "/artifact/*zip*/archive.zip"}
+	return archive.DownloadContext(ctx, w, nil)
+}
+
+func (b *Build) extractArtifacts(ctx context.Context, dir string, opts *ArtifactsOptions) ([]SavedArtifact, error) {
+	// zip needs random access, so keep the archive on disk.
+	tmp, err := ioutil.TempFile("", "jenkins-artifacts-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := b.DownloadArtifactsArchiveContext(ctx, tmp); err != nil {
+		return nil, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return nil, fmt.Errorf("reading artifact archive: %w", err)
+	}
+
+	known := map[string]Artifact{}
+	for _, a := range b.GetArtifacts() {
+		known[a.RelativePath] = a
+	}
+
+	var saved []SavedArtifact
+	for _, f := range zr.File {
+		// Entries are stored below a top-level "archive/" directory.
+		idx := strings.Index(f.Name, "/")
+		if idx < 0 || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		rel := f.Name[idx+1:]
+		if !opts.selects(rel) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return saved, err
+		}
+		a, ok := known[rel]
+		if !ok {
+			a = Artifact{Jenkins: b.Jenkins, Build: b, FileName: path.Base(rel), RelativePath: rel, Path: b.Base + "/artifact/" + rel}
+		}
+		s, err := extractArtifact(ctx, dir, a, f, opts.Verify)
+		if err != nil {
+			return saved, fmt.Errorf("extracting %s: %w", rel, err)
+		}
+		saved = append(saved, *s)
+	}
+	return saved, nil
+}
+
+func extractArtifact(ctx context.Context, dir string, a Artifact, f *zip.File, verify bool) (*SavedArtifact, error) {
+	localPath := artifactLocalPath(dir, a.RelativePath)
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return nil, err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	out, err := os.Create(localPath)
+	if err != nil {
+		return nil, err
+	}
+	h := newArtifactHash()
+	n, err := io.Copy(io.MultiWriter(out, h), rc)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DownloadResult{Verification: h.verification(), Written: n}
+	if verify {
+		if err := a.verify(ctx, &result.Verification); err != nil {
+			if errors.Is(err, ErrChecksumMismatch) {
+				os.Remove(localPath)
+			}
+			return nil, err
+		}
+	}
+	return &SavedArtifact{Artifact: a, LocalPath: localPath, Result: result}, nil
+}
+
+// artifactLocalPath maps a relative artifact path below dir, so paths such
+// as "../x" can't escape it.
+func artifactLocalPath(dir string, relativePath string) string {
+	return filepath.Join(dir, filepath.FromSlash(path.Clean("/"+relativePath)))
+}
+
+func (o *ArtifactsOptions) selects(relativePath string) bool {
+	included := len(o.Include) == 0
+	for _, pattern := range o.Include {
+		if matchGlob(pattern, relativePath) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	for _, pattern := range o.Exclude {
+		if matchGlob(pattern, relativePath) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid artifact pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchGlob reports whether name matches the Ant-style pattern. "**"
+// matches any number of path segments, other segments follow path.Match.
+func matchGlob(pattern string, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
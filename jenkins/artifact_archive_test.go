@@ -0,0 +1,137 @@
+package gojenkins
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		match         bool
+	}{
+		{"*.zip", "app.zip", true},
+		{"*.zip", "dist/app.zip", false},
+		{"**/*.zip", "app.zip", true},
+		{"**/*.zip", "dist/linux/app.zip", true},
+		{"linux-*/**", "linux-amd64/bin/app", true},
+		{"linux-*/**", "darwin-amd64/bin/app", false},
+		{"dist/**/app", "dist/app", true},
+		{"dist/?/app", "dist/ab/app", false},
+	}
+	for _, c := range cases {
+		if got := matchGlob(c.pattern, c.name); got != c.match {
+			t.Errorf("matchGlob(%q, %q) = %v", c.pattern, c.name, got)
+		}
+	}
+	if err := validateGlob("dist/[a"); err == nil {
+		t.Fatal("expected error for malformed pattern")
+	}
+}
+
+var testArtifacts = map[string]string{
+	"linux-amd64/bin/app":  "linux binary",
+	"darwin-amd64/bin/app": "darwin binary",
+	"linux-amd64/app.log":  "build log",
+	"../escape":            "outside",
+}
+
+func artifactsBuild(t *testing.T) (*httptest.Server, *Build, *int32) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range testArtifacts {
+		w, err := zw.Create("archive/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	var fingerprints struct {
+		FingerPrint []FingerPrintResponse `json:"fingerprint"`
+	}
+	for name, content := range testArtifacts {
+		sum := md5.Sum([]byte(content))
+		fingerprints.FingerPrint = append(fingerprints.FingerPrint, FingerPrintResponse{FileName: filepath.Base(name), Hash: hex.EncodeToString(sum[:])})
+	}
+	var fingerprintRequests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/job/app/1/api/json" {
+			atomic.AddInt32(&fingerprintRequests, 1)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(fingerprints)
+			return
+		}
+		rel := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/job/app/1/artifact/"), "/")
+		if rel == "*zip*/archive.zip" {
+			w.Write(archive.Bytes())
+			return
+		}
+		if content, ok := testArtifacts[rel]; ok {
+			w.Write([]byte(content))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+
+	build := &Build{Jenkins: CreateJenkins(nil, ts.URL), Base: "/job/app/1", Raw: new(BuildResponse)}
+	for name := range testArtifacts {
+		build.Raw.Artifacts = append(build.Raw.Artifacts, struct {
+			DisplayPath  string `json:"displayPath"`
+			FileName     string `json:"fileName"`
+			RelativePath string `json:"relativePath"`
+		}{FileName: filepath.Base(name), RelativePath: name})
+	}
+	return ts, build, &fingerprintRequests
+}
+
+func TestDownloadArtifacts(t *testing.T) {
+	for _, archive := range []bool{false, true} {
+		ts, build, fingerprintRequests := artifactsBuild(t)
+		dir := t.TempDir()
+		saved, err := build.DownloadArtifacts(dir, &ArtifactsOptions{
+			Include:     []string{"**/bin/*", "../*"},
+			Exclude:     []string{"darwin-*/**"},
+			Concurrency: 2,
+			Archive:     archive,
+			Verify:      true,
+		})
+		ts.Close()
+		if err != nil {
+			t.Fatal("DownloadArtifacts failed:", err)
+		}
+
+		var paths []string
+		for _, s := range saved {
+			rel, _ := filepath.Rel(dir, s.LocalPath)
+			paths = append(paths, filepath.ToSlash(rel))
+			data, _ := ioutil.ReadFile(s.LocalPath)
+			if string(data) != testArtifacts[s.Artifact.RelativePath] || s.Result.Written != int64(len(data)) {
+				t.Fatalf("unexpected content for %s: %q", s.Artifact.RelativePath, data)
+			}
+			if !s.Result.Verification.Verified {
+				t.Fatalf("%s was not verified", s.Artifact.RelativePath)
+			}
+		}
+		if n := atomic.LoadInt32(fingerprintRequests); n != 1 {
+			t.Fatalf("expected the fingerprints to be fetched once, got %d requests", n)
+		}
+		sort.Strings(paths)
+		if strings.Join(paths, ",") != "escape,linux-amd64/bin/app" {
+			t.Fatalf("unexpected files with archive=%v: %v", archive, paths)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package gojenkins
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func artifactServer(content []byte, interruptions int) (*httptest.Server, *[]string) {
+	var ranges []string
+	sum := fmt.Sprintf("%x", md5.Sum(content))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/fingerprint/"+sum+"/api/json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"hash":%q,"fileName":"app.bin"}`, sum)
+		case strings.HasPrefix(r.URL.Path, "/job/app/1/artifact/app.bin"):
+			ranges = append(ranges, r.Header.Get("Range"))
+			if interruptions > 0 {
+				interruptions--
+				// Announce the whole artifact but drop the connection halfway.
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				w.Write(content[:len(content)/2])
+				return
+			}
+			http.ServeContent(w, r, "app.bin", time.Time{}, bytes.NewReader(content))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return ts, &ranges
+}
+
+func TestArtifactDownloadResumes(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	ts, ranges := artifactServer(content, 1)
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	a := Artifact{Jenkins: j, FileName: "app.bin", Path: "/job/app/1/artifact/app.bin"}
+
+	var buf bytes.Buffer
+	var lastDownloaded, lastTotal int64
+	result, err := a.Download(&buf, &DownloadOptions{
+		MaxResumes: 1,
+		Verify:     true,
+		Progress:   func(downloaded, total int64) { lastDownloaded, lastTotal = downloaded, total },
+	})
+	if err != nil {
+		t.Fatal("Download failed:", err)
+	}
+	if result.Written != int64(len(content)) || !bytes.Equal(buf.Bytes(), content) {
+		t.Fatal("downloaded content does not match:", result.Written)
+	}
+	if !result.Verified || result.SHA256 != fmt.Sprintf("%x", sha256.Sum256(content)) {
+		t.Fatal("unexpected verification:", result.Verification)
+	}
+	if len(*ranges) != 2 || (*ranges)[0] != "" || (*ranges)[1] != fmt.Sprintf("bytes=%d-", len(content)/2) {
+		t.Fatal("unexpected range requests:", *ranges)
+	}
+	if lastDownloaded != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Fatal("unexpected progress:", lastDownloaded, lastTotal)
+	}
+}
+
+func TestArtifactDownloadInterrupted(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 4096)
+	ts, _ := artifactServer(content, 1)
+	defer ts.Close()
+
+	a := Artifact{Jenkins: CreateJenkins(nil, ts.URL), FileName: "app.bin", Path: "/job/app/1/artifact/app.bin"}
+	if _, err := a.Download(ioutil.Discard, nil); err == nil {
+		t.Fatal("expected error for interrupted download without resumes")
+	}
+}
+
+func TestArtifactDownloadToFile(t *testing.T) {
+	content := bytes.Repeat([]byte("abcdef"), 5000)
+	ts, ranges := artifactServer(content, 0)
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "app.bin")
+	if err := ioutil.WriteFile(path, content[:1000], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := Artifact{Jenkins: CreateJenkins(nil, ts.URL), FileName: "app.bin", Path: "/job/app/1/artifact/app.bin"}
+	result, err := a.DownloadToFile(path, &DownloadOptions{Verify: true})
+	if err != nil {
+		t.Fatal("DownloadToFile failed:", err)
+	}
+	if result.Written != int64(len(content)-1000) || (*ranges)[0] != "bytes=1000-" {
+		t.Fatal("partial file was not resumed:", result.Written, *ranges)
+	}
+	if !result.Verified || result.MD5 != fmt.Sprintf("%x", md5.Sum(content)) {
+		t.Fatal("resumed file was not verified as a whole:", result.Verification)
+	}
+	data, _ := ioutil.ReadFile(path)
+	if !bytes.Equal(data, content) {
+		t.Fatal("file content does not match")
+	}
+
+	// A complete file needs no further data.
+	if result, err = a.DownloadToFile(path, &DownloadOptions{Verify: true}); err != nil || result.Written != 0 {
+		t.Fatal("completed download was not recognised:", result, err)
+	}
+}
+
+func TestArtifactChecksumMismatch(t *testing.T) {
+	content := []byte("artifact")
+	ts, _ := artifactServer(content, 0)
+	defer ts.Close()
+
+	build := &Build{Raw: &BuildResponse{FingerPrint: []FingerPrintResponse{{FileName: "app.bin", Hash: "0123456789abcdef0123456789abcdef"}}}}
+	a := Artifact{Jenkins: CreateJenkins(nil, ts.URL), Build: build, FileName: "app.bin", Path: "/job/app/1/artifact/app.bin"}
+
+	path := filepath.Join(t.TempDir(), "app.bin")
+	saved, err := a.Save(path)
+	if saved || !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatal("expected checksum mismatch, got", saved, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("corrupted download was kept:", err)
+	}
+
+	// Without any fingerprint the artifact is saved unverified.
+	unrecorded := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/fingerprint/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	}))
+	defer unrecorded.Close()
+	a = Artifact{Jenkins: CreateJenkins(nil, unrecorded.URL), FileName: "app.bin", Path: "/job/app/1/artifact/app.bin"}
+	if saved, err = a.Save(path); !saved || err != nil {
+		t.Fatal("unfingerprinted artifact was not saved:", err)
+	}
+	if _, err := a.VerifyFile(path); !errors.Is(err, ErrNoFingerPrint) {
+		t.Fatal("expected missing fingerprint, got", err)
+	}
+}
+
+func TestArtifactChecksumMismatchPolledBuild(t *testing.T) {
+	content := []byte("corrupted")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/job/app/1/api/json":
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("tree") == "fingerprint[fileName,hash]" {
+				w.Write([]byte(`{"fingerprint":[{"fileName":"app.bin","hash":"0123456789abcdef0123456789abcdef"}]}`))
+				return
+			}
+			// Jenkins leaves out fingerprints at depth 1.
+			w.Write([]byte(`{"number":1,"artifacts":[{"fileName":"app.bin","relativePath":"app.bin"}]}`))
+		case strings.HasPrefix(r.URL.Path, "/job/app/1/artifact/app.bin"):
+			w.Write(content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	build := &Build{Jenkins: CreateJenkins(nil, ts.URL), Base: "/job/app/1", Raw: new(BuildResponse), Depth: 1}
+	if _, err := build.Poll(); err != nil {
+		t.Fatal("Poll failed:", err)
+	}
+	a := build.GetArtifacts()[0]
+	path := filepath.Join(t.TempDir(), "app.bin")
+	if saved, err := a.Save(path); saved || !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatal("expected checksum mismatch, got", saved, err)
+	}
+}
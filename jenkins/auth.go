@@ -0,0 +1,125 @@
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Authenticator adds credentials to every request sent to Jenkins,
+// including requests that follow a redirect.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+func (a *BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// APITokenAuth authenticates with a user's Jenkins API token.
+// Unlike a password, the token also works when Jenkins delegates logins to
+// an external identity provider.
+type APITokenAuth struct {
+	Username string
+	Token    string
+}
+
+func (a *APITokenAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Token)
+	return nil
+}
+
+// TokenSource supplies bearer tokens.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource that always returns the same token.
+type StaticToken string
+
+func (t StaticToken) Token(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// RefreshingTokenSource caches the token returned by Refresh and calls it
+// again once the token is about to expire. It is safe for concurrent use.
+// e.g. &RefreshingTokenSource{Refresh: oidcLogin, Leeway: time.Minute}
+type RefreshingTokenSource struct {
+	// Refresh fetches a new token and reports when it expires. A zero
+	// expiry means the token never expires.
+	Refresh func(ctx context.Context) (token string, expiry time.Time, err error)
+	// Leeway is how long before its expiry a token is refreshed.
+	Leeway time.Duration
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func (s *RefreshingTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.expiry.IsZero() || time.Now().Add(s.Leeway).Before(s.expiry)) {
+		return s.token, nil
+	}
+	if s.Refresh == nil {
+		return "", errors.New("RefreshingTokenSource has no Refresh function")
+	}
+	token, expiry, err := s.Refresh(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiry = token, expiry
+	return token, nil
+}
+
+// BearerTokenAuth sends a token from Source as "Authorization: Bearer",
+// e.g. an OIDC access token for a Jenkins behind SSO.
+type BearerTokenAuth struct {
+	Source TokenSource
+}
+
+func (a *BearerTokenAuth) Authenticate(req *http.Request) error {
+	token, err := a.Source.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// ProxyHeaderAuth identifies the user through a header set by a trusted
+// reverse proxy in front of Jenkins, e.g. X-Forwarded-User.
+type ProxyHeaderAuth struct {
+	Header string
+	User   string
+}
+
+func (a *ProxyHeaderAuth) Authenticate(req *http.Request) error {
+	req.Header.Set(a.Header, a.User)
+	return nil
+}
+
+// SetAuthenticator replaces the configured credentials. The cached crumb is
+// dropped because Jenkins issues crumbs per user.
+func (r *Requester) SetAuthenticator(auth Authenticator) *Requester {
+	r.Auth = auth
+	r.BasicAuth = nil
+	r.ResetCrumb()
+	return r
+}
+
+// authenticator returns Auth, falling back to BasicAuth.
+func (r *Requester) authenticator() Authenticator {
+	if r.Auth != nil {
+		return r.Auth
+	}
+	if r.BasicAuth != nil {
+		return r.BasicAuth
+	}
+	return nil
+}
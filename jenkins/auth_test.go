@@ -0,0 +1,152 @@
+package gojenkins
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthenticators(t *testing.T) {
+	var got *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+	defer ts.Close()
+
+	cases := []struct {
+		auth   Authenticator
+		header string
+		want   string
+	}{
+		{&BasicAuth{Username: "admin", Password: "secret"}, "Authorization", "Basic YWRtaW46c2VjcmV0"},
+		{&APITokenAuth{Username: "admin", Token: "11aa"}, "Authorization", "Basic YWRtaW46MTFhYQ=="},
+		{&BearerTokenAuth{Source: StaticToken("abc")}, "Authorization", "Bearer abc"},
+		{&ProxyHeaderAuth{Header: "X-Forwarded-User", User: "alice"}, "X-Forwarded-User", "alice"},
+	}
+	for _, c := range cases {
+		j := CreateJenkins(nil, ts.URL, c.auth)
+		if _, err := j.Poll(); err != nil {
+			t.Fatal("Poll failed:", err)
+		}
+		if v := got.Header.Get(c.header); v != c.want {
+			t.Fatalf("%T: expected %s %q, got %q", c.auth, c.header, c.want, v)
+		}
+	}
+}
+
+func TestAuthOnRedirect(t *testing.T) {
+	var got *http.Request
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}))
+	defer other.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/json":
+			http.Redirect(w, r, "/moved", http.StatusFound)
+		case "/moved":
+			got = r
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("{}"))
+		case "/job/app/api/json":
+			http.Redirect(w, r, other.URL+"/api/json", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+
+	cases := []struct {
+		auth   Authenticator
+		header string
+		want   string
+	}{
+		{&BearerTokenAuth{Source: StaticToken("abc")}, "Authorization", "Bearer abc"},
+		{&ProxyHeaderAuth{Header: "X-Forwarded-User", User: "alice"}, "X-Forwarded-User", "alice"},
+	}
+	for _, c := range cases {
+		j := CreateJenkins(nil, ts.URL, c.auth)
+		if _, err := j.Poll(); err != nil {
+			t.Fatal("Poll failed:", err)
+		}
+		if v := got.Header.Get(c.header); v != c.want {
+			t.Fatalf("%T: expected %s %q on same-host redirect, got %q", c.auth, c.header, c.want, v)
+		}
+
+		j.GetJob("app")
+		if got.Host == ts.Listener.Addr().String() {
+			t.Fatal("redirect to the other host was not followed")
+		}
+		if v := got.Header.Get(c.header); v != "" {
+			t.Fatalf("%T: credentials sent to another host: %s %q", c.auth, c.header, v)
+		}
+	}
+}
+
+func TestNoAuthOnDowngradingRedirect(t *testing.T) {
+	r := &Requester{Auth: &ProxyHeaderAuth{Header: "X-Forwarded-User", User: "alice"}}
+	from, _ := http.NewRequest("GET", "https://jenkins/api/json", nil)
+	to, _ := http.NewRequest("GET", "http://jenkins/api/json", nil)
+	to.Header.Set("Authorization", "Basic YWRtaW46c2VjcmV0")
+	to.Header.Set("X-Forwarded-User", "alice")
+	if err := r.redirectPolicyFunc(to, []*http.Request{from}); err != nil {
+		t.Fatal(err)
+	}
+	if len(to.Header) != 0 {
+		t.Fatal("credentials sent over http:", to.Header)
+	}
+}
+
+func TestRefreshingTokenSource(t *testing.T) {
+	refreshes := 0
+	expiry := time.Now().Add(time.Hour)
+	src := &RefreshingTokenSource{
+		Leeway: time.Minute,
+		Refresh: func(ctx context.Context) (string, time.Time, error) {
+			refreshes++
+			return "token", expiry, nil
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		if token, err := src.Token(context.Background()); err != nil || token != "token" {
+			t.Fatal("unexpected token:", token, err)
+		}
+	}
+	if refreshes != 1 {
+		t.Fatal("expected token to be cached, got", refreshes, "refreshes")
+	}
+
+	// Within the leeway the token counts as expired.
+	expiry = time.Now().Add(30 * time.Second)
+	src.expiry = expiry
+	src.Token(context.Background())
+	if refreshes != 2 {
+		t.Fatal("expected refresh of expiring token, got", refreshes, "refreshes")
+	}
+}
+
+func TestCreateJenkinsIgnoresUnknownAuth(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	j := CreateJenkins(nil, "http://jenkins", "admin", "s3cret", "x")
+	os.Stderr = stderr
+	w.Close()
+	out, _ := ioutil.ReadAll(r)
+
+	if j.Requester.authenticator() != nil {
+		t.Fatal("unsupported auth arguments were used")
+	}
+	if !strings.Contains(string(out), "count=3") || strings.Contains(string(out), "s3cret") {
+		t.Fatalf("unexpected log output: %q", out)
+	}
+}
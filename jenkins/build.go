@@ -2,10 +2,14 @@ package gojenkins
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,6 +26,27 @@ type parameter struct {
 	Value string
 }
 
+// UnmarshalJSON keeps non-string values, such as those of boolean
+// parameters, in their JSON form, e.g. "true".
+func (p *parameter) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Name  string
+		Value json.RawMessage
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	p.Name = raw.Name
+	p.Value = ""
+	if len(raw.Value) == 0 || string(raw.Value) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw.Value, &p.Value); err != nil {
+		p.Value = string(raw.Value)
+	}
+	return nil
+}
+
 type branch struct {
 	SHA1 string
 	Name string
@@ -45,49 +70,19 @@ type Culprit struct {
 }
 
 type generalObj struct {
-	Parameters              []parameter              `json:"parameters"`
-	Causes                  []map[string]interface{} `json:"causes"`
-	BuildsByBranchName      map[string]Builds        `json:"buildsByBranchName"`
-	LastBuiltRevision       BuildRevision            `json:"lastBuiltRevision"`
-	RemoteUrls              []string                 `json:"remoteUrls"`
-	ScmName                 string                   `json:"scmName"`
-	MercurialNodeName       string                   `json:"mercurialNodeName"`
-	MercurialRevisionNumber string                   `json:"mercurialRevisionNumber"`
-	Subdir                  interface{}              `json:"subdir"`
+	Parameters              []parameter       `json:"parameters"`
+	Causes                  []Cause           `json:"causes"`
+	BuildsByBranchName      map[string]Builds `json:"buildsByBranchName"`
+	LastBuiltRevision       BuildRevision     `json:"lastBuiltRevision"`
+	RemoteUrls              []string          `json:"remoteUrls"`
+	ScmName                 string            `json:"scmName"`
+	MercurialNodeName       string            `json:"mercurialNodeName"`
+	MercurialRevisionNumber string            `json:"mercurialRevisionNumber"`
+	Subdir                  interface{}       `json:"subdir"`
 	TotalCount              int64
 	UrlName                 string
 }
 
-type TestResult struct {
-	Duration  int64 `json:"duration"`
-	Empty     bool  `json:"empty"`
-	FailCount int64 `json:"failCount"`
-	PassCount int64 `json:"passCount"`
-	SkipCount int64 `json:"skipCount"`
-	Suites    []struct {
-		Cases []struct {
-			Age             int64       `json:"age"`
-			ClassName       string      `json:"className"`
-			Duration        int64       `json:"duration"`
-			ErrorDetails    interface{} `json:"errorDetails"`
-			ErrorStackTrace interface{} `json:"errorStackTrace"`
-			FailedSince     int64       `json:"failedSince"`
-			Name            string      `json:"name"`
-			Skipped         bool        `json:"skipped"`
-			SkippedMessage  interface{} `json:"skippedMessage"`
-			Status          string      `json:"status"`
-			Stderr          interface{} `json:"stderr"`
-			Stdout          interface{} `json:"stdout"`
-		} `json:"cases"`
-		Duration  int64       `json:"duration"`
-		ID        interface{} `json:"id"`
-		Name      string      `json:"name"`
-		Stderr    interface{} `json:"stderr"`
-		Stdout    interface{} `json:"stdout"`
-		Timestamp interface{} `json:"timestamp"`
-	} `json:"suites"`
-}
-
 type BuildResponse struct {
 	Actions   []generalObj
 	Artifacts []struct {
@@ -95,32 +90,11 @@ type BuildResponse struct {
 		FileName     string `json:"fileName"`
 		RelativePath string `json:"relativePath"`
 	} `json:"artifacts"`
-	Building  bool   `json:"building"`
-	BuiltOn   string `json:"builtOn"`
-	ChangeSet struct {
-		Items []struct {
-			AffectedPaths []string `json:"affectedPaths"`
-			Author        struct {
-				AbsoluteUrl string `json:"absoluteUrl"`
-				FullName    string `json:"fullName"`
-			} `json:"author"`
-			Comment  string `json:"comment"`
-			CommitID string `json:"commitId"`
-			Date     string `json:"date"`
-			ID       string `json:"id"`
-			Msg      string `json:"msg"`
-			Paths    []struct {
-				EditType string `json:"editType"`
-				File     string `json:"file"`
-			} `json:"paths"`
-			Timestamp int64 `json:"timestamp"`
-		} `json:"items"`
-		Kind      string `json:"kind"`
-		Revisions []struct {
-			Module   string
-			Revision int
-		} `json:"revision"`
-	} `json:"changeSet"`
+	Building  bool      `json:"building"`
+	BuiltOn   string    `json:"builtOn"`
+	ChangeSet ChangeSet `json:"changeSet"`
+	// ChangeSets is reported by Pipeline builds instead of ChangeSet.
+	ChangeSets        []ChangeSet `json:"changeSets"`
 	Culprits          []Culprit   `json:"culprits"`
 	Description       interface{} `json:"description"`
 	Duration          int64       `json:"duration"`
@@ -167,10 +141,11 @@ func (b *Build) GetArtifacts() []Artifact {
 	artifacts := make([]Artifact, len(b.Raw.Artifacts))
 	for i, artifact := range b.Raw.Artifacts {
 		artifacts[i] = Artifact{
-			Jenkins:  b.Jenkins,
-			Build:    b,
-			FileName: artifact.FileName,
-			Path:     b.Base + "/artifact/" + artifact.RelativePath,
+			Jenkins:      b.Jenkins,
+			Build:        b,
+			FileName:     artifact.FileName,
+			RelativePath: artifact.RelativePath,
+			Path:         b.Base + "/artifact/" + artifact.RelativePath,
 		}
 	}
 	return artifacts
@@ -181,45 +156,63 @@ func (b *Build) GetCulprits() []Culprit {
 }
 
 func (b *Build) Stop() (bool, error) {
-	if b.IsRunning() {
-		response, err := b.Jenkins.Requester.Post(b.Base+"/stop", nil, nil, nil)
+	return b.StopContext(context.Background())
+}
+
+func (b *Build) StopContext(ctx context.Context) (bool, error) {
+	if b.IsRunningContext(ctx) {
+		_, err := b.Jenkins.Requester.PostContext(ctx, b.Base+"/stop", nil, nil, nil)
 		if err != nil {
 			return false, err
 		}
-		return response.StatusCode == 200, nil
 	}
 	return true, nil
 }
 
 func (b *Build) GetConsoleOutput() string {
+	return b.GetConsoleOutputContext(context.Background())
+}
+
+func (b *Build) GetConsoleOutputContext(ctx context.Context) string {
 	url := b.Base + "/consoleText"
 	var content string
-	b.Jenkins.Requester.GetXML(url, &content, nil)
+	b.Jenkins.Requester.GetXMLContext(ctx, url, &content, nil)
 	return content
 }
 
 func (b *Build) GetConsoleOutputWithTimestamp() string {
+	return b.GetConsoleOutputWithTimestampContext(context.Background())
+}
+
+func (b *Build) GetConsoleOutputWithTimestampContext(ctx context.Context) string {
 	url := b.Base + "/timestamps"
 	queryMap := map[string]string{
 		"time":      "HH:mm:ss.S",
 		"appendLog": "",
 	}
 	var content string
-	b.Jenkins.Requester.GetXML(url, &content, queryMap)
+	b.Jenkins.Requester.GetXMLContext(ctx, url, &content, queryMap)
 	return content
 }
 
-func (b *Build) GetCauses() ([]map[string]interface{}, error) {
-	_, err := b.Poll()
+// Returns why the build was started, from all of its cause actions.
+func (b *Build) GetCauses() ([]Cause, error) {
+	return b.GetCausesContext(context.Background())
+}
+
+func (b *Build) GetCausesContext(ctx context.Context) ([]Cause, error) {
+	_, err := b.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
+	var causes []Cause
 	for _, a := range b.Raw.Actions {
-		if a.Causes != nil {
-			return a.Causes, nil
-		}
+		causes = append(causes, a.Causes...)
 	}
-	return nil, errors.New("No Causes")
+	if len(causes) == 0 {
+		return nil, errors.New("No Causes")
+	}
+	return causes, nil
 }
 
 func (b *Build) GetParameters() []parameter {
@@ -231,12 +224,107 @@ func (b *Build) GetParameters() []parameter {
 	return nil
 }
 
+// Queue a new build of the job with the parameters of this build, replacing
+// the values of the parameters in overrides. File parameters can't be
+// resubmitted. Returns the queue item of the new build; use
+// Jenkins.WaitForQueueItem to wait for it to start.
+func (b *Build) Rebuild(overrides map[string]string) (*QueueItem, error) {
+	return b.RebuildContext(context.Background(), overrides)
+}
+
+func (b *Build) RebuildContext(ctx context.Context, overrides map[string]string) (*QueueItem, error) {
+	params := map[string]string{}
+	for _, p := range b.GetParameters() {
+		params[p.Name] = p.Value
+	}
+	for name, value := range overrides {
+		params[name] = value
+	}
+	id, err := b.job().invoke(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return b.Jenkins.GetQueueItemContext(ctx, id)
+}
+
+// Replay a Pipeline build with mainScript in place of its Jenkinsfile.
+// loadedScripts replaces scripts pulled in with load, keyed by the names
+// shown on the Replay page, and must list all of them. Jenkins does not
+// report the queue item of a replay, so this waits until the new build
+// has started and returns it.
+func (b *Build) Replay(mainScript string, loadedScripts map[string]string, opts *WaitOptions) (*Build, error) {
+	return b.ReplayContext(context.Background(), mainScript, loadedScripts, opts)
+}
+
+func (b *Build) ReplayContext(ctx context.Context, mainScript string, loadedScripts map[string]string, opts *WaitOptions) (*Build, error) {
+	job := b.job()
+	if _, err := job.PollContext(ctx, Tree("nextBuildNumber")); err != nil {
+		return nil, err
+	}
+	next := job.Raw.NextBuildNumber
+
+	form := map[string]string{"mainScript": mainScript}
+	for name, script := range loadedScripts {
+		// The form field of a loaded script is its name with dots replaced.
+		form[strings.Replace(name, ".", "_", -1)] = script
+	}
+	data := url.Values{}
+	data.Set("json", makeJson(form))
+	if _, err := b.Jenkins.Requester.PostContext(ctx, b.Base+"/replay/run", strings.NewReader(data.Encode()), nil, nil); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel, interval := opts.context(ctx)
+	defer cancel()
+	// Other builds of the job may start meanwhile, so look for the one
+	// caused by replaying this build.
+	for number := next; ; {
+		build, err := job.GetBuildContext(ctx, number)
+		if err == nil {
+			if build.isReplayOf(b.GetBuildNumber()) {
+				return build, nil
+			}
+			number++
+			continue
+		}
+		if !IsNotFound(err) {
+			return nil, err
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, fmt.Errorf("waiting for replay of %s: %w", b.Base, err)
+		}
+	}
+}
+
+func (b *Build) isReplayOf(number int64) bool {
+	for _, a := range b.Raw.Actions {
+		for _, cause := range a.Causes {
+			if cause.Type == CauseReplay && cause.OriginalNumber == number {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// job returns the job of the build, deriving it from Base if unset.
+func (b *Build) job() *Job {
+	if b.Job != nil {
+		return b.Job
+	}
+	return &Job{Jenkins: b.Jenkins, Raw: new(JobResponse), Base: b.Base[:strings.LastIndex(b.Base, "/")]}
+}
+
 func (b *Build) GetInjectedEnvVars() (map[string]string, error) {
+	return b.GetInjectedEnvVarsContext(context.Background())
+}
+
+func (b *Build) GetInjectedEnvVarsContext(ctx context.Context) (map[string]string, error) {
 	var envVars struct {
 		EnvMap map[string]string `json:"envMap"`
 	}
 	endpoint := b.Base + "/injectedEnvVars"
-	_, err := b.Jenkins.Requester.GetJSON(endpoint, &envVars, nil)
+	_, err := b.Jenkins.Requester.GetJSONContext(ctx, endpoint, &envVars, nil)
 	if err != nil {
 		return envVars.EnvMap, err
 	}
@@ -244,24 +332,28 @@ func (b *Build) GetInjectedEnvVars() (map[string]string, error) {
 }
 
 func (b *Build) GetDownstreamBuilds() ([]*Build, error) {
+	return b.GetDownstreamBuildsContext(context.Background())
+}
+
+func (b *Build) GetDownstreamBuildsContext(ctx context.Context) ([]*Build, error) {
 	result := make([]*Build, 0)
-	downstreamJobs, err := b.Job.GetDownstreamJobs()
+	downstreamJobs, err := b.Job.GetDownstreamJobsContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	for _, job := range downstreamJobs {
-		allBuildIDs, err := job.GetAllBuildIds()
+		allBuildIDs, err := job.GetAllBuildIdsContext(ctx)
 		if err != nil {
 			return nil, err
 		}
 		for _, buildID := range allBuildIDs {
-			build, err := job.GetBuild(buildID.Number)
+			build, err := job.GetBuildContext(ctx, buildID.Number)
 			if err != nil {
 				return nil, err
 			}
-			upstreamBuild, _ := build.GetUpstreamBuild()
+			upstreamBuild, err := build.GetUpstreamBuildContext(ctx)
 			// cannot compare only id, it can be from different job
-			if b.GetUrl() == upstreamBuild.GetUrl() {
+			if err == nil && b.GetUrl() == upstreamBuild.GetUrl() {
 				result = append(result, build)
 				break
 			}
@@ -271,9 +363,13 @@ func (b *Build) GetDownstreamBuilds() ([]*Build, error) {
 }
 
 func (b *Build) GetDownstreamJobNames() []string {
+	return b.GetDownstreamJobNamesContext(context.Background())
+}
+
+func (b *Build) GetDownstreamJobNamesContext(ctx context.Context) []string {
 	result := make([]string, 0)
 	downstreamJobs := b.Job.GetDownstreamJobsMetadata()
-	fingerprints := b.GetAllFingerPrints()
+	fingerprints := b.GetAllFingerPrintsContext(ctx)
 	for _, fingerprint := range fingerprints {
 		for _, usage := range fingerprint.Raw.Usage {
 			for _, job := range downstreamJobs {
@@ -287,7 +383,11 @@ func (b *Build) GetDownstreamJobNames() []string {
 }
 
 func (b *Build) GetAllFingerPrints() []*FingerPrint {
-	b.Poll(3)
+	return b.GetAllFingerPrintsContext(context.Background())
+}
+
+func (b *Build) GetAllFingerPrintsContext(ctx context.Context) []*FingerPrint {
+	b.PollContext(ctx, 3)
 	result := make([]*FingerPrint, len(b.Raw.FingerPrint))
 	for i, f := range b.Raw.FingerPrint {
 		result[i] = &FingerPrint{Jenkins: b.Jenkins, Base: "/fingerprint/", Id: f.Hash, Raw: &f}
@@ -295,53 +395,61 @@ func (b *Build) GetAllFingerPrints() []*FingerPrint {
 	return result
 }
 
+// Returns the job of the build that started this one. Jobs in folders are
+// found too.
 func (b *Build) GetUpstreamJob() (*Job, error) {
-	causes, err := b.GetCauses()
+	return b.GetUpstreamJobContext(context.Background())
+}
+
+func (b *Build) GetUpstreamJobContext(ctx context.Context) (*Job, error) {
+	cause, err := b.GetCauseContext(ctx, CauseUpstream)
 	if err != nil {
 		return nil, err
 	}
-	if len(causes) > 0 {
-		if job, ok := causes[0]["upstreamProject"]; ok {
-			return b.Jenkins.GetJob(job.(string))
-		}
+	if cause == nil {
+		return nil, errors.New("Unable to get Upstream Job")
 	}
-	return nil, errors.New("Unable to get Upstream Job")
+	return b.Jenkins.upstreamJob(ctx, cause)
 }
 
+// Returns the number of the build that started this one, or 0.
 func (b *Build) GetUpstreamBuildNumber() (int64, error) {
-	causes, err := b.GetCauses()
-	if err != nil {
+	return b.GetUpstreamBuildNumberContext(context.Background())
+}
+
+func (b *Build) GetUpstreamBuildNumberContext(ctx context.Context) (int64, error) {
+	cause, err := b.GetCauseContext(ctx, CauseUpstream)
+	if err != nil || cause == nil {
 		return 0, err
 	}
-	if len(causes) > 0 {
-		if build, ok := causes[0]["upstreamBuild"]; ok {
-			switch t := build.(type) {
-			default:
-				return t.(int64), nil
-			case float64:
-				return int64(t), nil
-			}
-		}
-	}
-	return 0, nil
+	return cause.UpstreamBuild, nil
 }
 
 func (b *Build) GetUpstreamBuild() (*Build, error) {
-	job, err := b.GetUpstreamJob()
+	return b.GetUpstreamBuildContext(context.Background())
+}
+
+func (b *Build) GetUpstreamBuildContext(ctx context.Context) (*Build, error) {
+	cause, err := b.GetCauseContext(ctx, CauseUpstream)
 	if err != nil {
 		return nil, err
 	}
-	if job != nil {
-		buildNumber, err := b.GetUpstreamBuildNumber()
-		if err == nil {
-			return job.GetBuild(buildNumber)
-		}
+	if cause == nil || cause.UpstreamBuild == 0 {
+		return nil, errors.New("Build not found")
+	}
+	job, err := b.Jenkins.upstreamJob(ctx, cause)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("Build not found")
+	return job.GetBuildContext(ctx, cause.UpstreamBuild)
 }
 
 func (b *Build) GetMatrixRuns() ([]*Build, error) {
-	_, err := b.Poll(0)
+	return b.GetMatrixRunsContext(context.Background())
+}
+
+func (b *Build) GetMatrixRunsContext(ctx context.Context) ([]*Build, error) {
+	_, err := b.PollContext(ctx, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -351,17 +459,21 @@ func (b *Build) GetMatrixRuns() ([]*Build, error) {
 
 	for i, run := range runs {
 		result[i] = &Build{Jenkins: b.Jenkins, Job: b.Job, Raw: new(BuildResponse), Depth: 1, Base: "/" + r.FindString(run.URL)}
-		result[i].Poll()
+		result[i].PollContext(ctx)
 	}
 	return result, nil
 }
 
 func (b *Build) GetResultSet() (*TestResult, error) {
+	return b.GetResultSetContext(context.Background())
+}
+
+func (b *Build) GetResultSetContext(ctx context.Context) (*TestResult, error) {
 
 	url := b.Base + "/testReport"
 	var report TestResult
 
-	_, err := b.Jenkins.Requester.GetJSON(url, &report, nil)
+	_, err := b.Jenkins.Requester.GetJSONContext(ctx, url, &report, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -379,44 +491,41 @@ func (b *Build) GetDuration() int64 {
 	return b.Raw.Duration
 }
 
+// Returns the first revision checked out by the build, or "" if none is
+// known. See GetRevisions for builds with several checkouts.
 func (b *Build) GetRevision() string {
-	vcs := b.Raw.ChangeSet.Kind
-
-	if vcs == "git" || vcs == "hg" {
-		for _, a := range b.Raw.Actions {
-			if a.LastBuiltRevision.SHA1 != "" {
-				return a.LastBuiltRevision.SHA1
-			}
-			if a.MercurialRevisionNumber != "" {
-				return a.MercurialRevisionNumber
-			}
-		}
-	} else if vcs == "svn" {
-		return strconv.Itoa(b.Raw.ChangeSet.Revisions[0].Revision)
+	revisions := b.GetRevisions()
+	if len(revisions) == 0 {
+		return ""
 	}
-	return ""
+	return revisions[0].Revision
 }
 
+// Returns the SHA1 of the git branch built, or the name of the Mercurial
+// branch. It is "" for svn and if the branch is unknown.
 func (b *Build) GetRevisionBranch() string {
-	vcs := b.Raw.ChangeSet.Kind
-	if vcs == "git" {
-		for _, a := range b.Raw.Actions {
-			if len(a.LastBuiltRevision.Branch) > 0 && a.LastBuiltRevision.Branch[0].SHA1 != "" {
-				return a.LastBuiltRevision.Branch[0].SHA1
-			}
+	for _, a := range b.Raw.Actions {
+		if len(a.LastBuiltRevision.Branch) > 0 && a.LastBuiltRevision.Branch[0].SHA1 != "" {
+			return a.LastBuiltRevision.Branch[0].SHA1
 		}
-	} else {
-		panic("Not implemented")
 	}
-	return ""
+	return b.hgBranch()
 }
 
 func (b *Build) IsGood() bool {
-	return (!b.IsRunning() && b.Raw.Result == STATUS_SUCCESS)
+	return b.IsGoodContext(context.Background())
+}
+
+func (b *Build) IsGoodContext(ctx context.Context) bool {
+	return (!b.IsRunningContext(ctx) && b.Raw.Result == STATUS_SUCCESS)
 }
 
 func (b *Build) IsRunning() bool {
-	_, err := b.Poll()
+	return b.IsRunningContext(context.Background())
+}
+
+func (b *Build) IsRunningContext(ctx context.Context) bool {
+	_, err := b.PollContext(ctx)
 	if err != nil {
 		return false
 	}
@@ -424,15 +533,49 @@ func (b *Build) IsRunning() bool {
 }
 
 func (b *Build) SetDescription(description string) error {
+	return b.SetDescriptionContext(context.Background(), description)
+}
+
+func (b *Build) SetDescriptionContext(ctx context.Context, description string) error {
 	data := url.Values{}
 	data.Set("description", description)
-	_, err := b.Jenkins.Requester.Post(b.Base+"/submitDescription", bytes.NewBufferString(data.Encode()), nil, nil)
+	_, err := b.Jenkins.Requester.PostContext(ctx, b.Base+"/submitDescription", bytes.NewBufferString(data.Encode()), nil, nil)
 	return err
 }
 
-// Poll for current data. Optional parameter - depth.
+// Block until the build finishes and return its result, e.g. "SUCCESS".
+// The build is polled again in full once it is done.
+func (b *Build) Wait(opts *WaitOptions) (string, error) {
+	return b.WaitContext(context.Background(), opts)
+}
+
+func (b *Build) WaitContext(ctx context.Context, opts *WaitOptions) (string, error) {
+	ctx, cancel, interval := opts.context(ctx)
+	defer cancel()
+	for {
+		if _, err := b.PollContext(ctx, Tree("building,result")); err != nil {
+			return "", fmt.Errorf("waiting for build %s: %w", b.Base, err)
+		}
+		if !b.Raw.Building {
+			break
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return "", fmt.Errorf("waiting for build %s: %w", b.Base, err)
+		}
+	}
+	if _, err := b.PollContext(ctx); err != nil {
+		return "", err
+	}
+	return b.Raw.Result, nil
+}
+
+// Poll for current data. Optional parameters - depth, or a Tree selecting the fields to fetch.
 // More about depth here: https://wiki.jenkins-ci.org/display/JENKINS/Remote+access+API
 func (b *Build) Poll(options ...interface{}) (int, error) {
+	return b.PollContext(context.Background(), options...)
+}
+
+func (b *Build) PollContext(ctx context.Context, options ...interface{}) (int, error) {
 	depth := "-1"
 
 	for _, o := range options {
@@ -452,7 +595,8 @@ func (b *Build) Poll(options ...interface{}) (int, error) {
 	qr := map[string]string{
 		"depth": depth,
 	}
-	response, err := b.Jenkins.Requester.GetJSON(b.Base, b.Raw, qr)
+	qr = applyTree(qr, options)
+	response, err := b.Jenkins.Requester.GetJSONContext(ctx, b.Base, b.Raw, qr)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,86 @@
+package gojenkins
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRebuild(t *testing.T) {
+	var submitted map[string][]string
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/job/app/5/api/json":
+			w.Write([]byte(`{"number":5,"actions":[{"parameters":[{"name":"VERSION","value":"1.0"},{"name":"DRY_RUN","value":true}]}]}`))
+		case "/job/app/api/json":
+			w.Write([]byte(`{"name":"app","property":[{"parameterDefinitions":[{"name":"VERSION"},{"name":"DRY_RUN"}]}]}`))
+		case "/job/app/buildWithParameters":
+			r.ParseForm()
+			submitted = r.PostForm
+			w.Header().Set("Location", ts.URL+"/queue/item/31/")
+			w.WriteHeader(http.StatusCreated)
+		case "/queue/item/31/api/json":
+			w.Write([]byte(`{"id":31,"why":"In the quiet period"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	build := &Build{Jenkins: CreateJenkins(nil, ts.URL), Base: "/job/app/5", Raw: new(BuildResponse), Depth: 1}
+	if _, err := build.Poll(); err != nil {
+		t.Fatal("Poll failed:", err)
+	}
+	item, err := build.Rebuild(map[string]string{"VERSION": "1.1"})
+	if err != nil {
+		t.Fatal("Rebuild failed:", err)
+	}
+	if item.ID != 31 || item.Why != "In the quiet period" {
+		t.Fatal("unexpected queue item:", item)
+	}
+	if len(submitted) != 2 || submitted["VERSION"][0] != "1.1" || submitted["DRY_RUN"][0] != "true" {
+		t.Fatal("unexpected parameters:", submitted)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	var form map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/job/app/api/json":
+			w.Write([]byte(`{"name":"app","nextBuildNumber":8}`))
+		case "/job/app/3/replay/run":
+			r.ParseForm()
+			json.Unmarshal([]byte(r.PostForm.Get("json")), &form)
+		case "/job/app/8/api/json":
+			// Started by someone else meanwhile.
+			w.Write([]byte(`{"number":8,"actions":[{"causes":[{"_class":"hudson.model.Cause$UserIdCause"}]}]}`))
+		case "/job/app/9/api/json":
+			if form == nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(`{"number":9,"actions":[{"causes":[{"_class":"org.jenkinsci.plugins.workflow.cps.replay.ReplayCause","originalNumber":3}]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	build := &Build{Jenkins: CreateJenkins(nil, ts.URL), Base: "/job/app/3", Raw: &BuildResponse{Number: 3}}
+	replayed, err := build.Replay("echo 'patched'", map[string]string{"Script1.groovy": "return this"}, &WaitOptions{PollInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal("Replay failed:", err)
+	}
+	if replayed.GetBuildNumber() != 9 {
+		t.Fatal("found the wrong build:", replayed.GetBuildNumber())
+	}
+	if form["mainScript"] != "echo 'patched'" || form["Script1_groovy"] != "return this" {
+		t.Fatal("unexpected replay form:", form)
+	}
+}
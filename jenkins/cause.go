@@ -0,0 +1,214 @@
+package gojenkins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// CauseType tells what started a build.
+type CauseType int
+
+const (
+	// A cause this package doesn't know. Cause.Raw holds its fields.
+	CauseOther CauseType = iota
+	// Started by a user, see Cause.UserID.
+	CauseUser
+	// Started by another build, see Cause.UpstreamProject.
+	CauseUpstream
+	// Started on a schedule.
+	CauseTimer
+	// Started by an SCM change, from polling or a push notification.
+	CauseSCM
+	// Started through the remote build trigger, see Cause.Addr.
+	CauseRemote
+	// Replay of a Pipeline build, see Cause.OriginalNumber.
+	CauseReplay
+)
+
+func (t CauseType) String() string {
+	switch t {
+	case CauseUser:
+		return "user"
+	case CauseUpstream:
+		return "upstream"
+	case CauseTimer:
+		return "timer"
+	case CauseSCM:
+		return "scm"
+	case CauseRemote:
+		return "remote"
+	case CauseReplay:
+		return "replay"
+	}
+	return "other"
+}
+
+// Cause is a reason a build or queue item was started. Only the fields
+// matching Type are set.
+type Cause struct {
+	Type             CauseType `json:"-"`
+	Class            string    `json:"_class"`
+	ShortDescription string    `json:"shortDescription"`
+
+	UserID   string `json:"userId"`
+	UserName string `json:"userName"`
+
+	// UpstreamProject is the full name of the upstream job, e.g.
+	// "folder/app", and UpstreamURL its URL relative to Jenkins.
+	UpstreamProject string `json:"upstreamProject"`
+	UpstreamBuild   int64  `json:"upstreamBuild"`
+	UpstreamURL     string `json:"upstreamUrl"`
+	// UpstreamCauses are the causes of the upstream build.
+	UpstreamCauses []Cause `json:"upstreamCauses"`
+
+	Addr string `json:"addr"`
+	Note string `json:"note"`
+
+	OriginalNumber int64 `json:"originalNumber"`
+
+	// Raw holds all fields as reported by Jenkins.
+	Raw map[string]interface{} `json:"-"`
+}
+
+var causeTypesByClass = []struct {
+	suffix string
+	t      CauseType
+}{
+	{"$UserIdCause", CauseUser},
+	{"$UserCause", CauseUser},
+	{"UpstreamCause", CauseUpstream},
+	{"$TimerTriggerCause", CauseTimer},
+	{"$SCMTriggerCause", CauseSCM},
+	{"PushCause", CauseSCM},
+	{"BranchEventCause", CauseSCM},
+	{"$RemoteCause", CauseRemote},
+	{".ReplayCause", CauseReplay},
+}
+
+func (c *Cause) UnmarshalJSON(data []byte) error {
+	type cause Cause
+	var fields cause
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &fields.Raw); err != nil {
+		return err
+	}
+	*c = Cause(fields)
+	c.Type = c.causeType()
+	return nil
+}
+
+func (c *Cause) causeType() CauseType {
+	for _, ct := range causeTypesByClass {
+		if strings.HasSuffix(c.Class, ct.suffix) {
+			return ct.t
+		}
+	}
+	if c.Class != "" {
+		return CauseOther
+	}
+
+	// Jenkins leaves out _class when a tree is requested.
+	switch {
+	case c.UpstreamProject != "":
+		return CauseUpstream
+	case c.UserID != "" || c.UserName != "":
+		return CauseUser
+	case c.OriginalNumber != 0:
+		return CauseReplay
+	case c.Addr != "":
+		return CauseRemote
+	}
+	return CauseOther
+}
+
+// UpstreamChain returns the upstream causes leading to this one, starting
+// with c and then following UpstreamCauses. It is empty unless c is an
+// upstream cause.
+func (c *Cause) UpstreamChain() []*Cause {
+	var chain []*Cause
+	for next := c; next != nil && next.Type == CauseUpstream; {
+		chain = append(chain, next)
+		current := next
+		next = nil
+		for i := range current.UpstreamCauses {
+			if current.UpstreamCauses[i].Type == CauseUpstream {
+				next = &current.UpstreamCauses[i]
+				break
+			}
+		}
+	}
+	return chain
+}
+
+// Root returns the cause that started the chain of upstream builds leading
+// to c, e.g. the user who started the first build, or c itself.
+func (c *Cause) Root() *Cause {
+	chain := c.UpstreamChain()
+	if len(chain) == 0 {
+		return c
+	}
+	last := chain[len(chain)-1]
+	if len(last.UpstreamCauses) > 0 {
+		return &last.UpstreamCauses[0]
+	}
+	return last
+}
+
+func findCause(causes []Cause, t CauseType) *Cause {
+	for i := range causes {
+		if causes[i].Type == t {
+			return &causes[i]
+		}
+	}
+	return nil
+}
+
+// Returns the first cause of the given type, or nil.
+func (b *Build) GetCause(t CauseType) (*Cause, error) {
+	return b.GetCauseContext(context.Background(), t)
+}
+
+func (b *Build) GetCauseContext(ctx context.Context, t CauseType) (*Cause, error) {
+	causes, err := b.GetCausesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return findCause(causes, t), nil
+}
+
+// Returns the cause that started the chain of upstream builds leading to
+// this one, or its own first cause if it wasn't started by another build.
+func (b *Build) GetRootCause() (*Cause, error) {
+	return b.GetRootCauseContext(context.Background())
+}
+
+func (b *Build) GetRootCauseContext(ctx context.Context) (*Cause, error) {
+	causes, err := b.GetCausesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if upstream := findCause(causes, CauseUpstream); upstream != nil {
+		return upstream.Root(), nil
+	}
+	return &causes[0], nil
+}
+
+// upstreamJob gets the job of an upstream cause, which may be in a folder.
+func (j *Jenkins) upstreamJob(ctx context.Context, c *Cause) (*Job, error) {
+	if c.UpstreamURL != "" {
+		job := Job{Jenkins: j, Raw: new(JobResponse), Base: "/" + strings.Trim(c.UpstreamURL, "/")}
+		if _, err := job.PollContext(ctx); err != nil {
+			return nil, err
+		}
+		return &job, nil
+	}
+	if c.UpstreamProject == "" {
+		return nil, errors.New("Unable to get Upstream Job")
+	}
+	path := strings.Split(strings.Trim(c.UpstreamProject, "/"), "/")
+	return j.GetJobContext(ctx, path[len(path)-1], path[:len(path)-1]...)
+}
@@ -0,0 +1,103 @@
+package gojenkins
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCauseTypes(t *testing.T) {
+	var causes []Cause
+	err := json.Unmarshal([]byte(`[
+		{"_class":"hudson.model.Cause$UserIdCause","userId":"alice","userName":"Alice"},
+		{"_class":"hudson.triggers.TimerTrigger$TimerTriggerCause"},
+		{"_class":"hudson.triggers.SCMTrigger$SCMTriggerCause"},
+		{"_class":"com.cloudbees.jenkins.GitHubPushCause"},
+		{"_class":"hudson.model.Cause$RemoteCause","addr":"10.0.0.1","note":"nightly"},
+		{"_class":"org.jenkinsci.plugins.workflow.cps.replay.ReplayCause","originalNumber":3},
+		{"_class":"org.jenkinsci.plugins.workflow.support.steps.build.BuildUpstreamCause","upstreamProject":"a","upstreamBuild":1},
+		{"_class":"com.example.CustomCause","ticket":"OPS-1"},
+		{"upstreamProject":"b","upstreamBuild":2}]`), &causes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CauseType{CauseUser, CauseTimer, CauseSCM, CauseSCM, CauseRemote, CauseReplay, CauseUpstream, CauseOther, CauseUpstream}
+	for i, c := range causes {
+		if c.Type != want[i] {
+			t.Fatalf("cause %d is %s, want %s", i, c.Type, want[i])
+		}
+	}
+	if causes[0].UserID != "alice" || causes[4].Addr != "10.0.0.1" || causes[5].OriginalNumber != 3 || causes[7].Raw["ticket"] != "OPS-1" {
+		t.Fatal("unexpected cause fields:", causes)
+	}
+}
+
+func TestUpstreamCauses(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/job/deploy/4/api/json":
+			w.Write([]byte(`{"number":4,"actions":[{"_class":"hudson.model.ParametersAction"},{"causes":[
+				{"_class":"hudson.model.Cause$UserIdCause","userId":"bob"},
+				{"_class":"hudson.model.Cause$UpstreamCause","upstreamProject":"team/app","upstreamBuild":12,"upstreamUrl":"job/team/job/app/",
+				 "upstreamCauses":[{"_class":"hudson.model.Cause$UpstreamCause","upstreamProject":"team/lib","upstreamBuild":30,
+				   "upstreamCauses":[{"_class":"hudson.triggers.SCMTrigger$SCMTriggerCause","shortDescription":"Started by an SCM change"}]}]}]}]}`))
+		case "/job/deploy/5/api/json":
+			// Older Jenkins without upstreamUrl.
+			w.Write([]byte(`{"number":5,"actions":[{"causes":[{"_class":"hudson.model.Cause$UpstreamCause","upstreamProject":"team/app","upstreamBuild":12}]}]}`))
+		case "/job/deploy/6/api/json":
+			w.Write([]byte(`{"number":6,"actions":[{"causes":[{"_class":"hudson.model.Cause$UserIdCause","userId":"bob"}]}]}`))
+		case "/job/team/job/app/api/json":
+			w.Write([]byte(`{"name":"app","fullName":"team/app"}`))
+		case "/job/team/job/app/12/api/json":
+			w.Write([]byte(`{"number":12,"url":"http://jenkins/job/team/job/app/12/"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	newBuild := func(number string) *Build {
+		return &Build{Jenkins: j, Base: "/job/deploy/" + number, Raw: new(BuildResponse), Depth: 1}
+	}
+
+	for _, number := range []string{"4", "5"} {
+		upstream, err := newBuild(number).GetUpstreamBuild()
+		if err != nil {
+			t.Fatal("GetUpstreamBuild failed:", err)
+		}
+		if upstream.GetBuildNumber() != 12 || upstream.Base != "/job/team/job/app/12" {
+			t.Fatal("unexpected upstream build:", upstream.Base)
+		}
+	}
+
+	b := newBuild("4")
+	cause, err := b.GetCause(CauseUpstream)
+	if err != nil {
+		t.Fatal("GetCause failed:", err)
+	}
+	chain := cause.UpstreamChain()
+	if len(chain) != 2 || chain[1].UpstreamProject != "team/lib" || chain[1].UpstreamBuild != 30 {
+		t.Fatal("unexpected upstream chain:", chain)
+	}
+	root, err := b.GetRootCause()
+	if err != nil {
+		t.Fatal("GetRootCause failed:", err)
+	}
+	if root.Type != CauseSCM || root.ShortDescription != "Started by an SCM change" {
+		t.Fatal("unexpected root cause:", root)
+	}
+
+	manual := newBuild("6")
+	if number, err := manual.GetUpstreamBuildNumber(); number != 0 || err != nil {
+		t.Fatal("unexpected upstream build number:", number, err)
+	}
+	if _, err := manual.GetUpstreamBuild(); err == nil {
+		t.Fatal("found an upstream build of a build started by a user")
+	}
+	if root, _ := manual.GetRootCause(); root.UserID != "bob" {
+		t.Fatal("unexpected root cause:", root)
+	}
+}
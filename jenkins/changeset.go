@@ -0,0 +1,161 @@
+package gojenkins
+
+import (
+	"strconv"
+	"time"
+)
+
+// ChangeSet lists the commits a build picked up from one repository. Kind
+// is "git", "hg" or "svn".
+type ChangeSet struct {
+	Items []ChangeSetItem `json:"items"`
+	Kind  string          `json:"kind"`
+	// Revisions are the revisions of the checked out modules, for svn only.
+	Revisions []ChangeSetRevision `json:"revisions"`
+}
+
+type ChangeSetItem struct {
+	AffectedPaths []string        `json:"affectedPaths"`
+	Author        ChangeSetAuthor `json:"author"`
+	Comment       string          `json:"comment"`
+	CommitID      string          `json:"commitId"`
+	Date          string          `json:"date"`
+	ID            string          `json:"id"`
+	Msg           string          `json:"msg"`
+	Paths         []ChangeSetPath `json:"paths"`
+	Timestamp     int64           `json:"timestamp"`
+	// Revision is set for svn, Node, Rev and Branch for hg.
+	Revision int64  `json:"revision"`
+	Node     string `json:"node"`
+	Rev      int64  `json:"rev"`
+	Branch   string `json:"branch"`
+}
+
+type ChangeSetAuthor struct {
+	AbsoluteUrl string `json:"absoluteUrl"`
+	FullName    string `json:"fullName"`
+}
+
+// ChangeSetPath is a file touched by a commit. EditType is "add", "edit"
+// or "delete".
+type ChangeSetPath struct {
+	EditType string `json:"editType"`
+	File     string `json:"file"`
+}
+
+type ChangeSetRevision struct {
+	Module   string `json:"module"`
+	Revision int64  `json:"revision"`
+}
+
+var changeSetDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+}
+
+// Time returns when the change was committed, or the zero time if Jenkins
+// doesn't know.
+func (i *ChangeSetItem) Time() time.Time {
+	if i.Timestamp > 0 {
+		return time.Unix(0, i.Timestamp*int64(time.Millisecond))
+	}
+	for _, layout := range changeSetDateLayouts {
+		if t, err := time.Parse(layout, i.Date); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Message returns the commit message, which some SCMs report as Comment.
+func (i *ChangeSetItem) Message() string {
+	if i.Msg != "" {
+		return i.Msg
+	}
+	return i.Comment
+}
+
+// SCMRevision is a revision checked out by a build. Builds with several
+// checkouts, e.g. Pipelines, have one per repository.
+type SCMRevision struct {
+	Kind       string
+	RemoteURLs []string
+	Revision   string
+	// Branch is the name of the branch built, e.g. "origin/master", if known.
+	Branch string
+}
+
+// Returns the change sets of the build. Pipeline builds report one per
+// checked out repository, other builds at most one.
+func (b *Build) GetChangeSets() []ChangeSet {
+	if len(b.Raw.ChangeSets) > 0 {
+		return b.Raw.ChangeSets
+	}
+	if b.Raw.ChangeSet.Kind != "" || len(b.Raw.ChangeSet.Items) > 0 {
+		return []ChangeSet{b.Raw.ChangeSet}
+	}
+	return nil
+}
+
+// Returns the commits of all change sets of the build.
+func (b *Build) GetChanges() []ChangeSetItem {
+	var items []ChangeSetItem
+	for _, cs := range b.GetChangeSets() {
+		items = append(items, cs.Items...)
+	}
+	return items
+}
+
+// Returns the revisions checked out by the build, in the order Jenkins
+// reports them.
+func (b *Build) GetRevisions() []SCMRevision {
+	var revisions []SCMRevision
+	seen := map[string]bool{}
+	add := func(r SCMRevision) {
+		key := r.Kind + " " + r.Revision + " " + r.Branch
+		for _, u := range r.RemoteURLs {
+			key += " " + u
+		}
+		if !seen[key] {
+			seen[key] = true
+			revisions = append(revisions, r)
+		}
+	}
+
+	for _, a := range b.Raw.Actions {
+		if a.LastBuiltRevision.SHA1 != "" {
+			r := SCMRevision{Kind: "git", RemoteURLs: a.RemoteUrls, Revision: a.LastBuiltRevision.SHA1}
+			if len(a.LastBuiltRevision.Branch) > 0 {
+				r.Branch = a.LastBuiltRevision.Branch[0].Name
+			}
+			add(r)
+		}
+		if a.MercurialRevisionNumber != "" {
+			add(SCMRevision{Kind: "hg", Revision: a.MercurialRevisionNumber, Branch: b.hgBranch()})
+		}
+	}
+	for _, cs := range b.GetChangeSets() {
+		if cs.Kind != "svn" {
+			continue
+		}
+		for _, rev := range cs.Revisions {
+			add(SCMRevision{Kind: "svn", RemoteURLs: []string{rev.Module}, Revision: strconv.FormatInt(rev.Revision, 10)})
+		}
+	}
+	return revisions
+}
+
+func (b *Build) hgBranch() string {
+	for _, cs := range b.GetChangeSets() {
+		if cs.Kind != "hg" {
+			continue
+		}
+		for _, item := range cs.Items {
+			if item.Branch != "" {
+				return item.Branch
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package gojenkins
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func buildFromJSON(t *testing.T, data string) *Build {
+	raw := new(BuildResponse)
+	if err := json.Unmarshal([]byte(data), raw); err != nil {
+		t.Fatal(err)
+	}
+	return &Build{Raw: raw}
+}
+
+func TestPipelineChangeSets(t *testing.T) {
+	b := buildFromJSON(t, `{"actions":[
+		{"_class":"hudson.plugins.git.util.BuildData","lastBuiltRevision":{"SHA1":"a1b2","branch":[{"SHA1":"a1b2","name":"origin/main"}]},"remoteUrls":["https://git.example.com/app.git"]},
+		{"_class":"hudson.plugins.git.util.BuildData","lastBuiltRevision":{"SHA1":"c3d4","branch":[{"SHA1":"c3d4","name":"origin/release"}]},"remoteUrls":["https://git.example.com/lib.git"]},
+		{"_class":"hudson.plugins.git.util.BuildData","lastBuiltRevision":{"SHA1":"a1b2","branch":[{"SHA1":"a1b2","name":"origin/main"}]},"remoteUrls":["https://git.example.com/app.git"]}],
+	 "changeSets":[
+		{"kind":"git","items":[{"commitId":"a1b2","msg":"Fix login","timestamp":1600000000000,"author":{"fullName":"dev"},
+			"paths":[{"editType":"edit","file":"login.go"}]}]},
+		{"kind":"svn","items":[{"commitId":"812","revision":812,"msg":"Bump","date":"2020-09-13T12:26:40.123456Z"}],
+		 "revisions":[{"module":"https://svn.example.com/repo/trunk","revision":812}]}]}`)
+
+	sets := b.GetChangeSets()
+	if len(sets) != 2 || sets[0].Kind != "git" || sets[1].Kind != "svn" {
+		t.Fatal("unexpected change sets:", sets)
+	}
+	changes := b.GetChanges()
+	if len(changes) != 2 || changes[0].Author.FullName != "dev" || changes[0].Paths[0].File != "login.go" || changes[0].Message() != "Fix login" {
+		t.Fatal("unexpected changes:", changes)
+	}
+	if changes[0].Time().Unix() != 1600000000 || !changes[1].Time().Equal(time.Date(2020, 9, 13, 12, 26, 40, 123456000, time.UTC)) {
+		t.Fatal("unexpected commit times:", changes[0].Time(), changes[1].Time())
+	}
+
+	revisions := b.GetRevisions()
+	if len(revisions) != 3 {
+		t.Fatal("unexpected revisions:", revisions)
+	}
+	if r := revisions[1]; r.Kind != "git" || r.Revision != "c3d4" || r.Branch != "origin/release" || r.RemoteURLs[0] != "https://git.example.com/lib.git" {
+		t.Fatal("unexpected git revision:", r)
+	}
+	if r := revisions[2]; r.Kind != "svn" || r.Revision != "812" || r.RemoteURLs[0] != "https://svn.example.com/repo/trunk" {
+		t.Fatal("unexpected svn revision:", r)
+	}
+	if b.GetRevision() != "a1b2" || b.GetRevisionBranch() != "a1b2" {
+		t.Fatal("unexpected revision:", b.GetRevision(), b.GetRevisionBranch())
+	}
+}
+
+func TestChangeSetKinds(t *testing.T) {
+	hg := buildFromJSON(t, `{"actions":[{"mercurialNodeName":"9f8e7d","mercurialRevisionNumber":"42"}],
+		"changeSet":{"kind":"hg","items":[{"node":"9f8e7d","rev":42,"branch":"stable","msg":"Fix"}]}}`)
+	if hg.GetRevision() != "42" || hg.GetRevisionBranch() != "stable" || hg.GetChanges()[0].Node != "9f8e7d" {
+		t.Fatal("unexpected hg revision:", hg.GetRevisions())
+	}
+
+	// An svn build without changes and a build without any SCM must not panic.
+	svn := buildFromJSON(t, `{"changeSet":{"kind":"svn","items":[],"revisions":[]}}`)
+	none := buildFromJSON(t, `{"changeSet":{"items":[]}}`)
+	for _, b := range []*Build{svn, none} {
+		if b.GetRevision() != "" || b.GetRevisionBranch() != "" || len(b.GetChanges()) != 0 {
+			t.Fatal("unexpected revision:", b.GetRevisions())
+		}
+	}
+	if len(svn.GetChangeSets()) != 1 || len(none.GetChangeSets()) != 0 {
+		t.Fatal("unexpected change sets")
+	}
+}
@@ -0,0 +1,138 @@
+package gojenkins
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Delay between two polls of a running build's console log by default.
+const defaultConsolePollInterval = time.Second
+
+// ConsoleOptions controls how the console log of a build is followed.
+type ConsoleOptions struct {
+	// Start is the byte offset in the log to begin at.
+	Start int64
+	// PollInterval is the delay between requests while the build is
+	// running.
+	PollInterval time.Duration
+}
+
+// ConsoleChunk is a piece of console log. The last chunk sent on a
+// channel carries the error that stopped the stream, if any.
+type ConsoleChunk struct {
+	// Offset is the position of Text in the log.
+	Offset int64
+	Text   string
+	Err    error
+}
+
+// Get the console log from byte offset start using logText/progressiveText.
+// Returns the new text, the offset to continue from and whether Jenkins
+// will write more, i.e. the build is still running.
+func (b *Build) GetConsoleOutputFrom(start int64) (string, int64, bool, error) {
+	return b.GetConsoleOutputFromContext(context.Background(), start)
+}
+
+func (b *Build) GetConsoleOutputFromContext(ctx context.Context, start int64) (string, int64, bool, error) {
+	var content string
+	qr := map[string]string{"start": strconv.FormatInt(start, 10)}
+	response, err := b.Jenkins.Requester.GetContext(ctx, b.Base+"/logText/progressiveText", &content, qr)
+	if err != nil {
+		return "", start, false, err
+	}
+	next, err := strconv.ParseInt(response.Header.Get("X-Text-Size"), 10, 64)
+	if err != nil {
+		next = start + int64(len(content))
+	}
+	more := response.Header.Get("X-More-Data") == "true"
+	return content, next, more, nil
+}
+
+// Stream the console log until the build finishes. The returned channel is
+// closed when the build is done or ctx is cancelled.
+func (b *Build) FollowConsoleOutput(opts *ConsoleOptions) <-chan ConsoleChunk {
+	return b.FollowConsoleOutputContext(context.Background(), opts)
+}
+
+func (b *Build) FollowConsoleOutputContext(ctx context.Context, opts *ConsoleOptions) <-chan ConsoleChunk {
+	chunks := make(chan ConsoleChunk)
+	go func() {
+		defer close(chunks)
+		err := b.followConsole(ctx, opts, func(chunk ConsoleChunk) error {
+			select {
+			case chunks <- chunk:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			select {
+			case chunks <- ConsoleChunk{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return chunks
+}
+
+// Get a reader over the console log that reads until the build finishes.
+// Closing the reader stops polling Jenkins.
+func (b *Build) ConsoleReader(opts *ConsoleOptions) io.ReadCloser {
+	return b.ConsoleReaderContext(context.Background(), opts)
+}
+
+func (b *Build) ConsoleReaderContext(ctx context.Context, opts *ConsoleOptions) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	go func() {
+		err := b.followConsole(ctx, opts, func(chunk ConsoleChunk) error {
+			_, err := io.WriteString(pw, chunk.Text)
+			return err
+		})
+		pw.CloseWithError(err)
+	}()
+	return &consoleReader{PipeReader: pr, cancel: cancel}
+}
+
+type consoleReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (r *consoleReader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
+
+func (b *Build) followConsole(ctx context.Context, opts *ConsoleOptions, emit func(ConsoleChunk) error) error {
+	var o ConsoleOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultConsolePollInterval
+	}
+
+	start := o.Start
+	for {
+		text, next, more, err := b.GetConsoleOutputFromContext(ctx, start)
+		if err != nil {
+			return err
+		}
+		if text != "" {
+			if err := emit(ConsoleChunk{Offset: start, Text: text}); err != nil {
+				return err
+			}
+		}
+		start = next
+		if !more {
+			return nil
+		}
+		if err := sleepContext(ctx, o.PollInterval); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package gojenkins
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// consoleServer serves log one line per request, as if a running build
+// wrote it.
+func consoleServer(log []string) (*httptest.Server, *[]string) {
+	var starts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/job/app/1/logText/progressiveText/" {
+			http.NotFound(w, r)
+			return
+		}
+		start := r.URL.Query().Get("start")
+		starts = append(starts, start)
+		offset, _ := strconv.Atoi(start)
+
+		var written, size int
+		for i, line := range log {
+			size += len(line)
+			if size <= offset {
+				continue
+			}
+			w.Header().Set("X-Text-Size", strconv.Itoa(size))
+			if i < len(log)-1 {
+				w.Header().Set("X-More-Data", "true")
+			}
+			w.Write([]byte(line[len(line)-(size-offset):]))
+			written++
+			break
+		}
+		if written == 0 {
+			w.Header().Set("X-Text-Size", strconv.Itoa(size))
+		}
+	}))
+	return ts, &starts
+}
+
+func TestFollowConsoleOutput(t *testing.T) {
+	ts, starts := consoleServer([]string{"Started\n", "Building\n", "Finished: SUCCESS\n"})
+	defer ts.Close()
+
+	b := &Build{Jenkins: CreateJenkins(nil, ts.URL), Base: "/job/app/1", Raw: new(BuildResponse)}
+	var text strings.Builder
+	var offsets []int64
+	for chunk := range b.FollowConsoleOutput(&ConsoleOptions{PollInterval: time.Millisecond}) {
+		if chunk.Err != nil {
+			t.Fatal("FollowConsoleOutput failed:", chunk.Err)
+		}
+		offsets = append(offsets, chunk.Offset)
+		text.WriteString(chunk.Text)
+	}
+	if text.String() != "Started\nBuilding\nFinished: SUCCESS\n" {
+		t.Fatal("unexpected log:", text.String())
+	}
+	if strings.Join(*starts, ",") != "0,8,17" || offsets[2] != 17 {
+		t.Fatal("log was not fetched progressively:", *starts, offsets)
+	}
+}
+
+func TestConsoleReader(t *testing.T) {
+	ts, _ := consoleServer([]string{"Started\n", "Finished: SUCCESS\n"})
+	defer ts.Close()
+
+	b := &Build{Jenkins: CreateJenkins(nil, ts.URL), Base: "/job/app/1", Raw: new(BuildResponse)}
+	r := b.ConsoleReader(&ConsoleOptions{Start: 3, PollInterval: time.Millisecond})
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal("reading console failed:", err)
+	}
+	if string(data) != "rted\nFinished: SUCCESS\n" {
+		t.Fatal("unexpected log:", string(data))
+	}
+}
+
+func TestFollowConsoleOutputCancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Text-Size", "0")
+		w.Header().Set("X-More-Data", "true")
+	}))
+	defer ts.Close()
+
+	b := &Build{Jenkins: CreateJenkins(nil, ts.URL), Base: "/job/app/1", Raw: new(BuildResponse)}
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks := b.FollowConsoleOutputContext(ctx, &ConsoleOptions{PollInterval: time.Millisecond})
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	done := make(chan struct{})
+	go func() {
+		for range chunks {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not stop after cancellation")
+	}
+}
@@ -13,3 +13,14 @@ const (
 	RESULT_STATUS_SKIPPED = "SKIPPED"
 	STR_RE_SPLIT_VIEW     = "(.*)/view/([^/]*)/?"
 )
+
+// Status of Pipeline runs, stages and steps.
+const (
+	PIPELINE_STATUS_SUCCESS      = "SUCCESS"
+	PIPELINE_STATUS_FAILED       = "FAILED"
+	PIPELINE_STATUS_UNSTABLE     = "UNSTABLE"
+	PIPELINE_STATUS_ABORTED      = "ABORTED"
+	PIPELINE_STATUS_IN_PROGRESS  = "IN_PROGRESS"
+	PIPELINE_STATUS_NOT_EXECUTED = "NOT_EXECUTED"
+	PIPELINE_STATUS_PAUSED_INPUT = "PAUSED_PENDING_INPUT"
+)
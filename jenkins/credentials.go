@@ -2,8 +2,8 @@ package gojenkins
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
-	"errors"
 	"fmt"
 )
 
@@ -38,23 +38,25 @@ type CredentialsResponse struct {
 }
 
 func (c Credentials) Create(credentialsData string) error {
-	resp, err := c.Jenkins.Requester.Post(c.Base+"createCredentials", bytes.NewBufferString(credentialsData), c.Raw, nil)
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode != 200 {
-		return errors.New(fmt.Sprintf("Error with response code %d", resp.StatusCode))
-	}
-	return nil
+	return c.CreateContext(context.Background(), credentialsData)
+}
+
+func (c Credentials) CreateContext(ctx context.Context, credentialsData string) error {
+	_, err := c.Jenkins.Requester.PostContext(ctx, c.Base+"createCredentials", bytes.NewBufferString(credentialsData), c.Raw, nil)
+	return err
 }
 
 func (c Credentials) GetAll() ([]UserCredential, error) {
+	return c.GetAllContext(context.Background())
+}
+
+func (c Credentials) GetAllContext(ctx context.Context) ([]UserCredential, error) {
 	var data string
 	endpoint := c.Base + "api/xml"
 	qeuryString := map[string]string{
 		"depth": "1",
 	}
-	_, err := c.Jenkins.Requester.GetXML(endpoint, &data, qeuryString)
+	_, err := c.Jenkins.Requester.GetXMLContext(ctx, endpoint, &data, qeuryString)
 	if err != nil {
 		return nil, err
 	}
@@ -68,8 +70,12 @@ func (c Credentials) GetAll() ([]UserCredential, error) {
 
 // Remove /credentials/store/system/domain/_/credential/auto-test-888/doDelete
 func (c Credentials) Remove(credentialsID string) error {
+	return c.RemoveContext(context.Background(), credentialsID)
+}
+
+func (c Credentials) RemoveContext(ctx context.Context, credentialsID string) error {
 	endpoint := fmt.Sprintf("%scredential/%s/doDelete", c.Base, credentialsID)
-	_, err := c.Jenkins.Requester.Post(endpoint, nil, nil, nil)
+	_, err := c.Jenkins.Requester.PostContext(ctx, endpoint, nil, nil, nil)
 	if err != nil {
 		return err
 	}
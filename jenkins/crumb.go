@@ -0,0 +1,94 @@
+package gojenkins
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+)
+
+// crumb is a CSRF token issued by /crumbIssuer. An empty RequestField means
+// CSRF protection is disabled on the server.
+type crumb struct {
+	RequestField string `json:"crumbRequestField"`
+	Crumb        string `json:"crumb"`
+}
+
+func (r *Requester) getCrumb(ctx context.Context) (*crumb, error) {
+	r.crumbMu.Lock()
+	defer r.crumbMu.Unlock()
+
+	if r.crumb != nil {
+		return r.crumb, nil
+	}
+
+	c := new(crumb)
+	_, err := r.GetJSONContext(ctx, "/crumbIssuer", c, nil)
+	if err != nil && !IsNotFound(err) {
+		return nil, err
+	}
+	r.crumb = c
+	return c, nil
+}
+
+// ResetCrumb drops the cached crumb so the next POST fetches a new one.
+func (r *Requester) ResetCrumb() {
+	r.crumbMu.Lock()
+	r.crumb = nil
+	r.crumbMu.Unlock()
+}
+
+func (r *Requester) cookieJar() http.CookieJar {
+	r.jarOnce.Do(func() {
+		if r.jar == nil {
+			r.jar, _ = cookiejar.New(nil)
+		}
+	})
+	return r.jar
+}
+
+// doWithCrumb sends ar with a crumb attached. If Jenkins rejects the crumb,
+// e.g. because the session it was bound to expired, a fresh crumb is fetched
+// and the request is sent once more.
+func (r *Requester) doWithCrumb(ctx context.Context, ar *APIRequest, responseStruct interface{}, options ...interface{}) (*http.Response, error) {
+	var payload []byte
+	if ar.Payload != nil {
+		data, err := ioutil.ReadAll(ar.Payload)
+		if err != nil {
+			return nil, err
+		}
+		payload = data
+		ar.Payload = bytes.NewReader(payload)
+	}
+
+	if err := r.SetCrumbContext(ctx, ar); err != nil {
+		return nil, err
+	}
+	response, err := r.DoContext(ctx, ar, responseStruct, options...)
+	if !isInvalidCrumb(response, err) {
+		return response, err
+	}
+
+	r.ResetCrumb()
+	if err := r.SetCrumbContext(ctx, ar); err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		ar.Payload = bytes.NewReader(payload)
+	}
+	return r.DoContext(ctx, ar, responseStruct, options...)
+}
+
+func isInvalidCrumb(response *http.Response, err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		return false
+	}
+	if strings.Contains(apiErr.Body, "No valid crumb") {
+		return true
+	}
+	return response != nil && strings.Contains(response.Status, "No valid crumb")
+}
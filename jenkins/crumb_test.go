@@ -0,0 +1,102 @@
+package gojenkins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// crumbServer issues one crumb per session and rejects POSTs whose crumb
+// does not belong to the session cookie sent with them.
+type crumbServer struct {
+	sessions     int
+	crumbFetches int
+	posts        int
+	valid        map[string]string
+}
+
+func (s *crumbServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/crumbIssuer/api/json" {
+		s.crumbFetches++
+		s.sessions++
+		session := fmt.Sprintf("session-%d", s.sessions)
+		crumb := fmt.Sprintf("crumb-%d", s.sessions)
+		s.valid[session] = crumb
+		http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: session, Path: "/"})
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"crumbRequestField": "Jenkins-Crumb", "crumb": %q}`, crumb)
+		return
+	}
+	cookie, err := r.Cookie("JSESSIONID")
+	if err != nil || s.valid[cookie.Value] == "" || s.valid[cookie.Value] != r.Header.Get("Jenkins-Crumb") {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("<html><title>Error 403 No valid crumb was included in the request</title></html>"))
+		return
+	}
+	// The payload must survive a resend after the crumb was refreshed.
+	if body, _ := ioutil.ReadAll(r.Body); string(body) != "a=b" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.posts++
+}
+
+func TestCrumbIsCached(t *testing.T) {
+	s := &crumbServer{valid: map[string]string{}}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	for i := 0; i < 5; i++ {
+		if _, err := j.Requester.Post("/job/x/build", strings.NewReader("a=b"), nil, nil); err != nil {
+			t.Fatal("POST failed:", err)
+		}
+	}
+	if s.crumbFetches != 1 || s.posts != 5 {
+		t.Fatal("expected 1 crumb fetch for 5 posts, got", s.crumbFetches, "fetches and", s.posts, "posts")
+	}
+}
+
+func TestCrumbRefreshedWhenRejected(t *testing.T) {
+	s := &crumbServer{valid: map[string]string{}}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	if _, err := j.Requester.Post("/job/x/build", strings.NewReader("a=b"), nil, nil); err != nil {
+		t.Fatal("POST failed:", err)
+	}
+
+	// Expire the session, as a Jenkins restart would.
+	s.valid = map[string]string{}
+	if _, err := j.Requester.Post("/job/x/build", strings.NewReader("a=b"), nil, nil); err != nil {
+		t.Fatal("POST after session expiry failed:", err)
+	}
+	if s.crumbFetches != 2 || s.posts != 2 {
+		t.Fatal("expected crumb refresh, got", s.crumbFetches, "fetches and", s.posts, "posts")
+	}
+}
+
+func TestCrumbIssuerDisabled(t *testing.T) {
+	crumbFetches := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			crumbFetches++
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	for i := 0; i < 3; i++ {
+		if _, err := j.Requester.Post("/job/x/build", nil, nil, nil); err != nil {
+			t.Fatal("POST failed:", err)
+		}
+	}
+	if crumbFetches != 1 {
+		t.Fatal("expected a single crumb issuer lookup, got", crumbFetches)
+	}
+}
@@ -0,0 +1,92 @@
+package gojenkins
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Number of response body bytes kept in APIError.Body.
+const errorBodyExcerptSize = 512
+
+// APIError is returned for every Jenkins response with a non-2xx status code
+// or an X-Error header.
+type APIError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	// Message holds the X-Error header sent by Jenkins, if any.
+	Message string
+	// Body holds the beginning of the response body.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("jenkins: %s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// newAPIError builds an APIError from response, consuming up to
+// errorBodyExcerptSize bytes of its body if it is still readable.
+func newAPIError(response *http.Response) *APIError {
+	e := &APIError{
+		StatusCode: response.StatusCode,
+		Message:    response.Header.Get("X-Error"),
+	}
+	if response.Request != nil {
+		e.Method = response.Request.Method
+		e.URL = response.Request.URL.String()
+	}
+	if response.Body != nil {
+		excerpt, _ := ioutil.ReadAll(io.LimitReader(response.Body, errorBodyExcerptSize))
+		e.Body = strings.TrimSpace(string(excerpt))
+	}
+	return e
+}
+
+// StatusCode returns the HTTP status code carried by err, or 0 if err is not
+// an *APIError.
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err is a 404 response from Jenkins.
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether err is a 401 response from Jenkins.
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether err is a 403 response from Jenkins.
+func IsForbidden(err error) bool {
+	return StatusCode(err) == http.StatusForbidden
+}
+
+// IsConflict reports whether err is a 409 response from Jenkins.
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
+
+// IsBadRequest reports whether err is a 400 response from Jenkins.
+func IsBadRequest(err error) bool {
+	return StatusCode(err) == http.StatusBadRequest
+}
+
+// IsServerError reports whether err is a 5xx response from Jenkins.
+func IsServerError(err error) bool {
+	code := StatusCode(err)
+	return code >= 500 && code < 600
+}
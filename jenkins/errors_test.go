@@ -0,0 +1,61 @@
+package gojenkins
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetJobNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such job", http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	_, err := CreateJenkins(nil, ts.URL).GetJob("missing")
+	if !IsNotFound(err) {
+		t.Fatal("expected not found error, got:", err)
+	}
+	if IsConflict(err) || IsUnauthorized(err) || IsServerError(err) {
+		t.Fatal("not found error matched another status helper:", err)
+	}
+	apiErr := err.(*APIError)
+	if apiErr.Method != "GET" || apiErr.URL != ts.URL+"/job/missing/api/json" {
+		t.Fatal("unexpected request in error:", apiErr.Method, apiErr.URL)
+	}
+	if apiErr.Body != "no such job" {
+		t.Fatal("unexpected body excerpt:", apiErr.Body)
+	}
+}
+
+func TestXErrorHeader(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("X-Error", "A job already exists with the name 'dup'")
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	_, err := CreateJenkins(nil, ts.URL).CreateJob("<project/>", "dup")
+	if !IsBadRequest(err) {
+		t.Fatal("expected bad request error, got:", err)
+	}
+	if msg := err.(*APIError).Message; msg != "A job already exists with the name 'dup'" {
+		t.Fatal("unexpected X-Error message:", msg)
+	}
+}
+
+func TestDecodeErrorReturned(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": `))
+	}))
+	defer ts.Close()
+
+	if _, err := CreateJenkins(nil, ts.URL).GetJob("broken"); err == nil {
+		t.Fatal("expected decode error for truncated JSON")
+	}
+}
@@ -1,6 +1,7 @@
 package gojenkins
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
@@ -32,7 +33,11 @@ type FingerPrintResponse struct {
 }
 
 func (f FingerPrint) Valid() (bool, error) {
-	status, err := f.Poll()
+	return f.ValidContext(context.Background())
+}
+
+func (f FingerPrint) ValidContext(ctx context.Context) (bool, error) {
+	status, err := f.PollContext(ctx)
 
 	if err != nil {
 		return false, err
@@ -45,7 +50,11 @@ func (f FingerPrint) Valid() (bool, error) {
 }
 
 func (f FingerPrint) ValidateForBuild(filename string, build *Build) (bool, error) {
-	valid, err := f.Valid()
+	return f.ValidateForBuildContext(context.Background(), filename, build)
+}
+
+func (f FingerPrint) ValidateForBuildContext(ctx context.Context, filename string, build *Build) (bool, error) {
+	valid, err := f.ValidContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -65,7 +74,11 @@ func (f FingerPrint) ValidateForBuild(filename string, build *Build) (bool, erro
 }
 
 func (f FingerPrint) GetInfo() (*FingerPrintResponse, error) {
-	_, err := f.Poll()
+	return f.GetInfoContext(context.Background())
+}
+
+func (f FingerPrint) GetInfoContext(ctx context.Context) (*FingerPrintResponse, error) {
+	_, err := f.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +86,11 @@ func (f FingerPrint) GetInfo() (*FingerPrintResponse, error) {
 }
 
 func (f FingerPrint) Poll() (int, error) {
-	response, err := f.Jenkins.Requester.GetJSON(f.Base+f.Id, f.Raw, nil)
+	return f.PollContext(context.Background())
+}
+
+func (f FingerPrint) PollContext(ctx context.Context) (int, error) {
+	response, err := f.Jenkins.Requester.GetJSONContext(ctx, f.Base+f.Id, f.Raw, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,169 @@
+package gojenkins
+
+import (
+	"context"
+	"sort"
+	"strconv"
+)
+
+// FlakyTestOptions controls which builds FindFlakyTests looks at.
+type FlakyTestOptions struct {
+	// Builds is how many of the newest completed builds are scanned, 20 if zero.
+	Builds int
+}
+
+// FlakyTest describes a test that both passed and failed on the same
+// revision, or went from passing to failing and back (or the other way) in
+// the scanned builds. A test that broke once and stayed broken is not
+// flaky. Skipped runs are ignored.
+type FlakyTest struct {
+	Name     string
+	Runs     int
+	Failures int
+	// Flips counts outcome changes between consecutive runs of the test.
+	Flips int
+	// Revisions lists the revisions on which the test both passed and
+	// failed, the strongest sign of flakiness.
+	Revisions []string
+	// Score is the share of consecutive runs whose outcome differs, from
+	// 0 to 1. A test failing once in an otherwise green history scores
+	// 2/(Runs-1); one alternating on every build scores 1.
+	Score float64
+	// FailedBuilds are the numbers of the builds in which the test failed.
+	FailedBuilds []int64
+}
+
+// FlakyTestReport lists the flaky tests found in a job's history. Tests
+// that flipped on the same revision come first, then by descending Score.
+type FlakyTestReport struct {
+	// Builds are the numbers of the builds with test results, oldest first.
+	Builds []int64
+	Tests  []FlakyTest
+}
+
+// How many running builds FindFlakyTests skips at most.
+const flakyRunningBuilds = 5
+
+// flakyRevisionTree selects what Build.GetRevision needs.
+const flakyRevisionTree = Tree("actions[lastBuiltRevision[SHA1],mercurialRevisionNumber],changeSet[kind,revisions[*]],changeSets[kind,revisions[*]]")
+
+type testRun struct {
+	build    int64
+	revision string
+	failed   bool
+}
+
+// Scan the newest builds of the job for tests that flip between passing
+// and failing, on the same revision or back and forth across builds.
+// Opts.Builds completed builds are scanned unless more than a few of the
+// newest builds are still running.
+func (j *Job) FindFlakyTests(opts *FlakyTestOptions) (*FlakyTestReport, error) {
+	return j.FindFlakyTestsContext(context.Background(), opts)
+}
+
+func (j *Job) FindFlakyTestsContext(ctx context.Context, opts *FlakyTestOptions) (*FlakyTestReport, error) {
+	limit := 20
+	if opts != nil && opts.Builds > 0 {
+		limit = opts.Builds
+	}
+
+	// Fetch a few more builds than needed to skip those still running.
+	summaries, err := j.GetBuildSummariesContext(ctx, limit+flakyRunningBuilds)
+	if err != nil {
+		return nil, err
+	}
+	var numbers []int64
+	for _, summary := range summaries {
+		if summary.Building || summary.Result == "" {
+			continue
+		}
+		numbers = append(numbers, summary.Number)
+	}
+	sort.Slice(numbers, func(i, k int) bool { return numbers[i] > numbers[k] })
+	if len(numbers) > limit {
+		numbers = numbers[:limit]
+	}
+
+	report := &FlakyTestReport{}
+	runs := map[string][]testRun{}
+	for i := len(numbers) - 1; i >= 0; i-- {
+		build := &Build{Jenkins: j.Jenkins, Job: j, Raw: new(BuildResponse), Base: j.Base + "/" + strconv.FormatInt(numbers[i], 10)}
+		if _, err := build.PollContext(ctx, flakyRevisionTree); err != nil {
+			return nil, err
+		}
+		result, err := build.GetResultSetContext(ctx)
+		if IsNotFound(err) {
+			// No tests were recorded, e.g. the build failed before running them.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Builds = append(report.Builds, numbers[i])
+		revision := build.GetRevision()
+		for _, c := range result.Cases() {
+			if !c.IsFailed() && !c.IsPassed() {
+				continue
+			}
+			name := c.FullName()
+			runs[name] = append(runs[name], testRun{build: numbers[i], revision: revision, failed: c.IsFailed()})
+		}
+	}
+
+	for name, history := range runs {
+		if test, ok := flakyTest(name, history); ok {
+			report.Tests = append(report.Tests, test)
+		}
+	}
+	sort.Slice(report.Tests, func(i, k int) bool {
+		a, b := report.Tests[i], report.Tests[k]
+		if (len(a.Revisions) > 0) != (len(b.Revisions) > 0) {
+			return len(a.Revisions) > 0
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Name < b.Name
+	})
+	return report, nil
+}
+
+// flakyTest summarizes the runs of a test, oldest first, and reports
+// whether it is flaky.
+func flakyTest(name string, history []testRun) (FlakyTest, bool) {
+	test := FlakyTest{Name: name, Runs: len(history)}
+	outcomes := map[string][2]bool{}
+	var revisions []string
+	for i, run := range history {
+		if run.failed {
+			test.Failures++
+			test.FailedBuilds = append(test.FailedBuilds, run.build)
+		}
+		if i > 0 && run.failed != history[i-1].failed {
+			test.Flips++
+		}
+		if run.revision == "" {
+			continue
+		}
+		seen, ok := outcomes[run.revision]
+		if !ok {
+			revisions = append(revisions, run.revision)
+		}
+		if run.failed {
+			seen[1] = true
+		} else {
+			seen[0] = true
+		}
+		outcomes[run.revision] = seen
+	}
+	for _, revision := range revisions {
+		if seen := outcomes[revision]; seen[0] && seen[1] {
+			test.Revisions = append(test.Revisions, revision)
+		}
+	}
+	if test.Flips < 2 && len(test.Revisions) == 0 {
+		return test, false
+	}
+	test.Score = float64(test.Flips) / float64(test.Runs-1)
+	return test, true
+}
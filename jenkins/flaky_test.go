@@ -0,0 +1,77 @@
+package gojenkins
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFindFlakyTests(t *testing.T) {
+	// Build 1 falls outside the scanned builds, build 6 recorded no tests
+	// and build 7 is still running.
+	responses := map[string]string{
+		"/job/app/api/json": `{"allBuilds":[{"number":7,"building":true},{"number":6,"result":"FAILURE"},{"number":5,"result":"UNSTABLE"},
+			{"number":4,"result":"SUCCESS"},{"number":3,"result":"SUCCESS"},{"number":2,"result":"UNSTABLE"},{"number":1,"result":"SUCCESS"}]}`,
+	}
+	revisions := []string{"aaa", "aaa", "bbb", "bbb", "ccc", "ddd"}
+	for i, revision := range revisions {
+		responses[fmt.Sprintf("/job/app/%d/api/json", i+1)] = fmt.Sprintf(`{"actions":[{"lastBuiltRevision":{"SHA1":"%s"}}]}`, revision)
+	}
+	statuses := [][4]string{
+		{"PASSED", "FAILED", "PASSED", "PASSED"},
+		{"PASSED", "PASSED", "PASSED", "FAILED"},
+		{"PASSED", "FAILED", "PASSED", "PASSED"},
+		{"SKIPPED", "FIXED", "PASSED", "PASSED"},
+		{"PASSED", "PASSED", "FAILED", "REGRESSION"},
+	}
+	for i, s := range statuses {
+		responses[fmt.Sprintf("/job/app/%d/testReport/api/json", i+1)] = fmt.Sprintf(`{"suites":[{"name":"pkg.ClientTest","cases":[
+			{"className":"pkg.ClientTest","name":"testStable","status":"%s"},
+			{"className":"pkg.ClientTest","name":"testRace","status":"%s"},
+			{"className":"pkg.ClientTest","name":"testBroken","status":"%s"},
+			{"className":"pkg.ClientTest","name":"testBounce","status":"%s"}]}]}`, s[0], s[1], s[2], s[3])
+	}
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tree := r.URL.Query().Get("tree"); tree != "" {
+			queries = append(queries, r.URL.Path+" "+tree)
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	job := &Job{Jenkins: CreateJenkins(nil, ts.URL), Base: "/job/app", Raw: new(JobResponse)}
+	report, err := job.FindFlakyTests(&FlakyTestOptions{Builds: 5})
+	if err != nil {
+		t.Fatal("FindFlakyTests failed:", err)
+	}
+	if fmt.Sprint(report.Builds) != "[2 3 4 5]" {
+		t.Fatal("unexpected builds scanned:", report.Builds)
+	}
+	if len(queries) != 6 || queries[0] != "/job/app/api/json allBuilds[number,result,building,timestamp,duration,url]{0,10}" ||
+		queries[1] != "/job/app/2/api/json "+string(flakyRevisionTree) {
+		t.Fatal("unexpected queries:", queries)
+	}
+
+	// testBroken broke once and stayed broken, which is a regression.
+	if len(report.Tests) != 2 {
+		t.Fatal("unexpected flaky tests:", report.Tests)
+	}
+	race, bounce := report.Tests[0], report.Tests[1]
+	if race.Name != "pkg.ClientTest.testRace" || race.Flips != 2 || fmt.Sprint(race.Revisions) != "[bbb]" {
+		t.Fatal("unexpected result for testRace:", race)
+	}
+	if bounce.Name != "pkg.ClientTest.testBounce" || bounce.Flips != 2 || len(bounce.Revisions) != 0 || fmt.Sprint(bounce.FailedBuilds) != "[2 5]" {
+		t.Fatal("unexpected result for testBounce:", bounce)
+	}
+	if race.Score != float64(2)/3 || bounce.Score != race.Score {
+		t.Fatal("unexpected scores:", race.Score, bounce.Score)
+	}
+}
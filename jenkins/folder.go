@@ -1,8 +1,7 @@
 package gojenkins
 
 import (
-	"errors"
-	"strconv"
+	"context"
 	"strings"
 )
 
@@ -32,6 +31,10 @@ func (f *Folder) GetName() string {
 }
 
 func (f *Folder) Create(name string) (*Folder, error) {
+	return f.CreateContext(context.Background(), name)
+}
+
+func (f *Folder) CreateContext(ctx context.Context, name string) (*Folder, error) {
 	mode := "com.cloudbees.hudson.plugins.folder.Folder"
 	data := map[string]string{
 		"name":   name,
@@ -42,19 +45,22 @@ func (f *Folder) Create(name string) (*Folder, error) {
 			"mode": mode,
 		}),
 	}
-	r, err := f.Jenkins.Requester.Post(f.parentBase()+"/createItem", nil, f.Raw, data)
+	_, err := f.Jenkins.Requester.PostContext(ctx, f.parentBase()+"/createItem", nil, f.Raw, data)
 	if err != nil {
 		return nil, err
 	}
-	if r.StatusCode == 200 {
-		f.Poll()
-		return f, nil
+	if _, err := f.PollContext(ctx); err != nil {
+		return nil, err
 	}
-	return nil, errors.New(strconv.Itoa(r.StatusCode))
+	return f, nil
 }
 
 func (f *Folder) Poll() (int, error) {
-	response, err := f.Jenkins.Requester.GetJSON(f.Base, f.Raw, nil)
+	return f.PollContext(context.Background())
+}
+
+func (f *Folder) PollContext(ctx context.Context) (int, error) {
+	response, err := f.Jenkins.Requester.GetJSONContext(ctx, f.Base, f.Raw, nil)
 	if err != nil {
 		return 0, err
 	}
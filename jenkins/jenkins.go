@@ -1,12 +1,11 @@
 package gojenkins
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
 )
 
@@ -21,24 +20,22 @@ type Jenkins struct {
 	Version   string
 	Raw       *ExecutorResponse
 	Requester *Requester
+	// Logger receives the client's log messages. Use SetLogger to change it
+	// together with the Requester's.
+	Logger Logger
 }
 
-// Loggers
-var (
-	Info    *log.Logger
-	Warning *log.Logger
-	Error   *log.Logger
-)
-
 // Init Method. Should be called after creating a Jenkins Instance.
 // e.g jenkins := CreateJenkins("url").Init()
 // HTTP Client is set here, Connection to jenkins is tested here.
 func (j *Jenkins) Init() (*Jenkins, error) {
-	j.initLoggers()
+	return j.InitContext(context.Background())
+}
 
+func (j *Jenkins) InitContext(ctx context.Context) (*Jenkins, error) {
 	// Check Connection
 	j.Raw = new(ExecutorResponse)
-	rsp, err := j.Requester.GetJSON("/", j.Raw, nil)
+	rsp, err := j.Requester.GetJSONContext(ctx, "/", j.Raw, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -51,23 +48,13 @@ func (j *Jenkins) Init() (*Jenkins, error) {
 	return j, nil
 }
 
-func (j *Jenkins) initLoggers() {
-	Info = log.New(os.Stdout,
-		"INFO: ",
-		log.Ldate|log.Ltime|log.Lshortfile)
-
-	Warning = log.New(os.Stdout,
-		"WARNING: ",
-		log.Ldate|log.Ltime|log.Lshortfile)
-
-	Error = log.New(os.Stderr,
-		"ERROR: ",
-		log.Ldate|log.Ltime|log.Lshortfile)
-}
-
 // Get Basic Information About Jenkins
 func (j *Jenkins) Info() (*ExecutorResponse, error) {
-	_, err := j.Requester.Get("/", j.Raw, nil)
+	return j.InfoContext(context.Background())
+}
+
+func (j *Jenkins) InfoContext(ctx context.Context) (*ExecutorResponse, error) {
+	_, err := j.Requester.GetJSONContext(ctx, "/", j.Raw, nil)
 
 	if err != nil {
 		return nil, err
@@ -81,6 +68,10 @@ func (j *Jenkins) Info() (*ExecutorResponse, error) {
 // By Default JNLPLauncher is created
 // Multiple labels should be separated by blanks
 func (j *Jenkins) CreateNode(name string, numExecutors int, description string, remoteFS string, label string, options ...interface{}) (*Node, error) {
+	return j.CreateNodeContext(context.Background(), name, numExecutors, description, remoteFS, label, options...)
+}
+
+func (j *Jenkins) CreateNodeContext(ctx context.Context, name string, numExecutors int, description string, remoteFS string, label string, options ...interface{}) (*Node, error) {
 	params := map[string]string{"method": "JNLPLauncher"}
 
 	if len(options) > 0 {
@@ -138,34 +129,39 @@ func (j *Jenkins) CreateNode(name string, numExecutors int, description string,
 		}),
 	}
 
-	resp, err := j.Requester.Post("/computer/doCreateItem", nil, nil, qr)
+	_, err := j.Requester.PostContext(ctx, "/computer/doCreateItem", nil, nil, qr)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode < 400 {
-		_, err := node.Poll()
-		if err != nil {
-			return nil, err
-		}
-		return node, nil
+	_, err = node.PollContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New(strconv.Itoa(resp.StatusCode))
+	return node, nil
 }
 
 // Delete a Jenkins slave node
 func (j *Jenkins) DeleteNode(name string) (bool, error) {
+	return j.DeleteNodeContext(context.Background(), name)
+}
+
+func (j *Jenkins) DeleteNodeContext(ctx context.Context, name string) (bool, error) {
 	node := Node{Jenkins: j, Raw: new(NodeResponse), Base: "/computer/" + name}
-	return node.Delete()
+	return node.DeleteContext(ctx)
 }
 
 // Create a new folder
 // This folder can be nested in other parent folders
 // Example: jenkins.CreateFolder("newFolder", "grandparentFolder", "parentFolder")
 func (j *Jenkins) CreateFolder(name string, parents ...string) (*Folder, error) {
+	return j.CreateFolderContext(context.Background(), name, parents...)
+}
+
+func (j *Jenkins) CreateFolderContext(ctx context.Context, name string, parents ...string) (*Folder, error) {
 	folderObj := &Folder{Jenkins: j, Raw: new(FolderResponse), Base: "/job/" + strings.Join(append(parents, name), "/job/")}
-	folder, err := folderObj.Create(name)
+	folder, err := folderObj.CreateContext(ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -175,11 +171,15 @@ func (j *Jenkins) CreateFolder(name string, parents ...string) (*Folder, error)
 // Create a new job in the folder
 // Example: jenkins.CreateJobInFolder("<config></config>", "newJobName", "myFolder", "parentFolder")
 func (j *Jenkins) CreateJobInFolder(config string, jobName string, parentIDs ...string) (*Job, error) {
+	return j.CreateJobInFolderContext(context.Background(), config, jobName, parentIDs...)
+}
+
+func (j *Jenkins) CreateJobInFolderContext(ctx context.Context, config string, jobName string, parentIDs ...string) (*Job, error) {
 	jobObj := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + strings.Join(append(parentIDs, jobName), "/job/")}
 	qr := map[string]string{
 		"name": jobName,
 	}
-	job, err := jobObj.Create(config, qr)
+	job, err := jobObj.CreateContext(ctx, config, qr)
 	if err != nil {
 		return nil, err
 	}
@@ -191,6 +191,10 @@ func (j *Jenkins) CreateJobInFolder(config string, jobName string, parentIDs ...
 // takes name as string as second parameter
 // e.g jenkins.CreateJob("<config></config>","newJobName")
 func (j *Jenkins) CreateJob(config string, options ...interface{}) (*Job, error) {
+	return j.CreateJobContext(context.Background(), config, options...)
+}
+
+func (j *Jenkins) CreateJobContext(ctx context.Context, config string, options ...interface{}) (*Job, error) {
 	qr := make(map[string]string)
 	if len(options) > 0 {
 		qr["name"] = options[0].(string)
@@ -198,7 +202,7 @@ func (j *Jenkins) CreateJob(config string, options ...interface{}) (*Job, error)
 		return nil, errors.New("Error Creating Job, job name is missing")
 	}
 	jobObj := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + qr["name"]}
-	job, err := jobObj.Create(config, qr)
+	job, err := jobObj.CreateContext(ctx, config, qr)
 	if err != nil {
 		return nil, err
 	}
@@ -208,69 +212,91 @@ func (j *Jenkins) CreateJob(config string, options ...interface{}) (*Job, error)
 // Rename a job.
 // First parameter job old name, Second parameter job new name.
 func (j *Jenkins) RenameJob(job string, name string) *Job {
+	return j.RenameJobContext(context.Background(), job, name)
+}
+
+func (j *Jenkins) RenameJobContext(ctx context.Context, job string, name string) *Job {
 	jobObj := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + job}
-	jobObj.Rename(name)
+	jobObj.RenameContext(ctx, name)
 	return &jobObj
 }
 
 // Create a copy of a job.
 // First parameter Name of the job to copy from, Second parameter new job name.
 func (j *Jenkins) CopyJob(copyFrom string, newName string) (*Job, error) {
+	return j.CopyJobContext(context.Background(), copyFrom, newName)
+}
+
+func (j *Jenkins) CopyJobContext(ctx context.Context, copyFrom string, newName string) (*Job, error) {
 	job := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + copyFrom}
-	_, err := job.Poll()
+	_, err := job.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return job.Copy(newName)
+	return job.CopyContext(ctx, newName)
 }
 
 // Delete a job.
 func (j *Jenkins) DeleteJob(name string) (bool, error) {
+	return j.DeleteJobContext(context.Background(), name)
+}
+
+func (j *Jenkins) DeleteJobContext(ctx context.Context, name string) (bool, error) {
 	job := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + name}
-	return job.Delete()
+	return job.DeleteContext(ctx)
 }
 
 // Invoke a job.
 // First parameter job name, second parameter is optional Build parameters.
 func (j *Jenkins) BuildJob(name string, options ...interface{}) (int64, error) {
+	return j.BuildJobContext(context.Background(), name, options...)
+}
+
+func (j *Jenkins) BuildJobContext(ctx context.Context, name string, options ...interface{}) (int64, error) {
 	job := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + name}
 	var params map[string]string
 	if len(options) > 0 {
 		params, _ = options[0].(map[string]string)
 	}
-	return job.InvokeSimple(params)
+	return job.InvokeSimpleContext(ctx, params)
 }
 
 func (j *Jenkins) GetNode(name string) (*Node, error) {
+	return j.GetNodeContext(context.Background(), name)
+}
+
+func (j *Jenkins) GetNodeContext(ctx context.Context, name string) (*Node, error) {
 	node := Node{Jenkins: j, Raw: new(NodeResponse), Base: "/computer/" + name}
-	status, err := node.Poll()
+	_, err := node.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if status == 200 {
-		return &node, nil
-	}
-	return nil, errors.New("No node found")
+	return &node, nil
 }
 
 func (j *Jenkins) GetLabel(name string) (*Label, error) {
+	return j.GetLabelContext(context.Background(), name)
+}
+
+func (j *Jenkins) GetLabelContext(ctx context.Context, name string) (*Label, error) {
 	label := Label{Jenkins: j, Raw: new(LabelResponse), Base: "/label/" + name}
-	status, err := label.Poll()
+	_, err := label.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if status == 200 {
-		return &label, nil
-	}
-	return nil, errors.New("No label found")
+	return &label, nil
 }
 
 func (j *Jenkins) GetBuild(jobName string, number int64) (*Build, error) {
-	job, err := j.GetJob(jobName)
+	return j.GetBuildContext(context.Background(), jobName, number)
+}
+
+func (j *Jenkins) GetBuildContext(ctx context.Context, jobName string, number int64) (*Build, error) {
+	job, err := j.GetJobContext(ctx, jobName)
 	if err != nil {
 		return nil, err
 	}
-	build, err := job.GetBuild(number)
+	build, err := job.GetBuildContext(ctx, number)
 
 	if err != nil {
 		return nil, err
@@ -279,49 +305,59 @@ func (j *Jenkins) GetBuild(jobName string, number int64) (*Build, error) {
 }
 
 func (j *Jenkins) GetJob(id string, parentIDs ...string) (*Job, error) {
+	return j.GetJobContext(context.Background(), id, parentIDs...)
+}
+
+func (j *Jenkins) GetJobContext(ctx context.Context, id string, parentIDs ...string) (*Job, error) {
 	job := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + strings.Join(append(parentIDs, id), "/job/")}
-	status, err := job.Poll()
+	_, err := job.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if status == 200 {
-		return &job, nil
-	}
-	return nil, errors.New(strconv.Itoa(status))
+	return &job, nil
 }
 
 func (j *Jenkins) GetSubJob(parentId string, childId string) (*Job, error) {
+	return j.GetSubJobContext(context.Background(), parentId, childId)
+}
+
+func (j *Jenkins) GetSubJobContext(ctx context.Context, parentId string, childId string) (*Job, error) {
 	job := Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/" + parentId + "/job/" + childId}
-	status, err := job.Poll()
+	_, err := job.PollContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("trouble polling job: %v", err)
-	}
-	if status == 200 {
-		return &job, nil
+		return nil, fmt.Errorf("trouble polling job: %w", err)
 	}
-	return nil, errors.New(strconv.Itoa(status))
+	return &job, nil
 }
 
 func (j *Jenkins) GetFolder(id string, parents ...string) (*Folder, error) {
+	return j.GetFolderContext(context.Background(), id, parents...)
+}
+
+func (j *Jenkins) GetFolderContext(ctx context.Context, id string, parents ...string) (*Folder, error) {
 	folder := Folder{Jenkins: j, Raw: new(FolderResponse), Base: "/job/" + strings.Join(append(parents, id), "/job/")}
-	status, err := folder.Poll()
+	_, err := folder.PollContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("trouble polling folder: %v", err)
-	}
-	if status == 200 {
-		return &folder, nil
+		return nil, fmt.Errorf("trouble polling folder: %w", err)
 	}
-	return nil, errors.New(strconv.Itoa(status))
+	return &folder, nil
+}
+
+// Optional parameter - a Tree relative to /computer, e.g. Tree("computer[displayName,offline]"),
+// instead of fetching every node with depth 1. The tree must include displayName.
+func (j *Jenkins) GetAllNodes(options ...interface{}) ([]*Node, error) {
+	return j.GetAllNodesContext(context.Background(), options...)
 }
 
-func (j *Jenkins) GetAllNodes() ([]*Node, error) {
+func (j *Jenkins) GetAllNodesContext(ctx context.Context, options ...interface{}) ([]*Node, error) {
 	computers := new(Computers)
 
 	qr := map[string]string{
 		"depth": "1",
 	}
+	qr = applyTree(qr, options)
 
-	_, err := j.Requester.GetJSON("/computer", computers, qr)
+	_, err := j.Requester.GetJSONContext(ctx, "/computer", computers, qr)
 	if err != nil {
 		return nil, err
 	}
@@ -334,23 +370,47 @@ func (j *Jenkins) GetAllNodes() ([]*Node, error) {
 	return nodes, nil
 }
 
+// Get the status of every node, fetching only the fields of NodeStatus.
+func (j *Jenkins) GetAllNodeStatuses() ([]NodeStatus, error) {
+	return j.GetAllNodeStatusesContext(context.Background())
+}
+
+func (j *Jenkins) GetAllNodeStatusesContext(ctx context.Context) ([]NodeStatus, error) {
+	var computers struct {
+		Computers []NodeStatus `json:"computer"`
+	}
+	_, err := j.Requester.GetJSONTreeContext(ctx, "/computer", &computers, nil)
+	if err != nil {
+		return nil, err
+	}
+	return computers.Computers, nil
+}
+
 // Get all builds Numbers and URLS for a specific job.
 // There are only build IDs here,
 // To get all the other info of the build use jenkins.GetBuild(job,buildNumber)
 // or job.GetBuild(buildNumber)
 func (j *Jenkins) GetAllBuildIds(job string) ([]JobBuild, error) {
-	jobObj, err := j.GetJob(job)
+	return j.GetAllBuildIdsContext(context.Background(), job)
+}
+
+func (j *Jenkins) GetAllBuildIdsContext(ctx context.Context, job string) ([]JobBuild, error) {
+	jobObj, err := j.GetJobContext(ctx, job)
 	if err != nil {
 		return nil, err
 	}
-	return jobObj.GetAllBuildIds()
+	return jobObj.GetAllBuildIdsContext(ctx)
 }
 
 // Get Only Array of Job Names, Color, URL
 // Does not query each single Job.
 func (j *Jenkins) GetAllJobNames() ([]InnerJob, error) {
+	return j.GetAllJobNamesContext(context.Background())
+}
+
+func (j *Jenkins) GetAllJobNamesContext(ctx context.Context) ([]InnerJob, error) {
 	exec := Executor{Raw: new(ExecutorResponse), Jenkins: j}
-	_, err := j.Requester.GetJSON("/", exec.Raw, nil)
+	_, err := j.Requester.GetJSONContext(ctx, "/", exec.Raw, nil)
 
 	if err != nil {
 		return nil, err
@@ -362,8 +422,12 @@ func (j *Jenkins) GetAllJobNames() ([]InnerJob, error) {
 // Get All Possible Job Objects.
 // Each job will be queried.
 func (j *Jenkins) GetAllJobs() ([]*Job, error) {
+	return j.GetAllJobsContext(context.Background())
+}
+
+func (j *Jenkins) GetAllJobsContext(ctx context.Context) ([]*Job, error) {
 	exec := Executor{Raw: new(ExecutorResponse), Jenkins: j}
-	_, err := j.Requester.GetJSON("/", exec.Raw, nil)
+	_, err := j.Requester.GetJSONContext(ctx, "/", exec.Raw, nil)
 
 	if err != nil {
 		return nil, err
@@ -371,7 +435,7 @@ func (j *Jenkins) GetAllJobs() ([]*Job, error) {
 
 	jobs := make([]*Job, len(exec.Raw.Jobs))
 	for i, job := range exec.Raw.Jobs {
-		ji, err := j.GetJob(job.Name)
+		ji, err := j.GetJobContext(ctx, job.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -382,8 +446,12 @@ func (j *Jenkins) GetAllJobs() ([]*Job, error) {
 
 // Returns a Queue
 func (j *Jenkins) GetQueue() (*Queue, error) {
+	return j.GetQueueContext(context.Background())
+}
+
+func (j *Jenkins) GetQueueContext(ctx context.Context) (*Queue, error) {
 	q := &Queue{Jenkins: j, Raw: new(queueResponse), Base: j.GetQueueUrl()}
-	_, err := q.Poll()
+	_, err := q.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -396,15 +464,23 @@ func (j *Jenkins) GetQueueUrl() string {
 
 // Get Artifact data by Hash
 func (j *Jenkins) GetArtifactData(id string) (*FingerPrintResponse, error) {
+	return j.GetArtifactDataContext(context.Background(), id)
+}
+
+func (j *Jenkins) GetArtifactDataContext(ctx context.Context, id string) (*FingerPrintResponse, error) {
 	fp := FingerPrint{Jenkins: j, Base: "/fingerprint/", Id: id, Raw: new(FingerPrintResponse)}
-	return fp.GetInfo()
+	return fp.GetInfoContext(ctx)
 }
 
 // Returns the list of all plugins installed on the Jenkins server.
 // You can supply depth parameter, to limit how much data is returned.
 func (j *Jenkins) GetPlugins(depth int) (*Plugins, error) {
+	return j.GetPluginsContext(context.Background(), depth)
+}
+
+func (j *Jenkins) GetPluginsContext(ctx context.Context, depth int) (*Plugins, error) {
 	p := Plugins{Jenkins: j, Raw: new(PluginResponse), Base: "/pluginManager", Depth: depth}
-	_, err := p.Poll()
+	_, err := p.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -414,7 +490,11 @@ func (j *Jenkins) GetPlugins(depth int) (*Plugins, error) {
 // Check if the plugin is installed on the server.
 // Depth level 1 is used. If you need to go deeper, you can use GetPlugins, and iterate through them.
 func (j *Jenkins) HasPlugin(name string) (*Plugin, error) {
-	p, err := j.GetPlugins(1)
+	return j.HasPluginContext(context.Background(), name)
+}
+
+func (j *Jenkins) HasPluginContext(ctx context.Context, name string) (*Plugin, error) {
+	p, err := j.GetPluginsContext(ctx, 1)
 
 	if err != nil {
 		return nil, err
@@ -424,8 +504,12 @@ func (j *Jenkins) HasPlugin(name string) (*Plugin, error) {
 
 // Verify FingerPrint
 func (j *Jenkins) ValidateFingerPrint(id string) (bool, error) {
+	return j.ValidateFingerPrintContext(context.Background(), id)
+}
+
+func (j *Jenkins) ValidateFingerPrintContext(ctx context.Context, id string) (bool, error) {
 	fp := FingerPrint{Jenkins: j, Base: "/fingerprint/", Id: id, Raw: new(FingerPrintResponse)}
-	valid, err := fp.Valid()
+	valid, err := fp.ValidContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -436,9 +520,13 @@ func (j *Jenkins) ValidateFingerPrint(id string) (bool, error) {
 }
 
 func (j *Jenkins) GetView(name string) (*View, error) {
+	return j.GetViewContext(context.Background(), name)
+}
+
+func (j *Jenkins) GetViewContext(ctx context.Context, name string) (*View, error) {
 	url := "/view/" + name
 	view := View{Jenkins: j, Raw: new(ViewResponse), Base: url}
-	_, err := view.Poll()
+	_, err := view.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -446,13 +534,17 @@ func (j *Jenkins) GetView(name string) (*View, error) {
 }
 
 func (j *Jenkins) GetAllViews() ([]*View, error) {
-	_, err := j.Poll()
+	return j.GetAllViewsContext(context.Background())
+}
+
+func (j *Jenkins) GetAllViewsContext(ctx context.Context) ([]*View, error) {
+	_, err := j.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	views := make([]*View, len(j.Raw.Views))
 	for i, v := range j.Raw.Views {
-		views[i], _ = j.GetView(v.Name)
+		views[i], _ = j.GetViewContext(ctx, v.Name)
 	}
 	return views, nil
 }
@@ -468,6 +560,10 @@ func (j *Jenkins) GetAllViews() ([]*View, error) {
 // 		gojenkins.PIPELINE_VIEW
 // Example: jenkins.CreateView("newView",gojenkins.LIST_VIEW)
 func (j *Jenkins) CreateView(name string, viewType string) (*View, error) {
+	return j.CreateViewContext(context.Background(), name, viewType)
+}
+
+func (j *Jenkins) CreateViewContext(ctx context.Context, name string, viewType string) (*View, error) {
 	view := &View{Jenkins: j, Raw: new(ViewResponse), Base: "/view/" + name}
 	endpoint := "/createView"
 	data := map[string]string{
@@ -479,20 +575,20 @@ func (j *Jenkins) CreateView(name string, viewType string) (*View, error) {
 			"mode": viewType,
 		}),
 	}
-	r, err := j.Requester.Post(endpoint, nil, view.Raw, data)
+	_, err := j.Requester.PostContext(ctx, endpoint, nil, view.Raw, data)
 
 	if err != nil {
 		return nil, err
 	}
-
-	if r.StatusCode == 200 {
-		return j.GetView(name)
-	}
-	return nil, errors.New(strconv.Itoa(r.StatusCode))
+	return j.GetViewContext(ctx, name)
 }
 
 func (j *Jenkins) Poll() (int, error) {
-	resp, err := j.Requester.GetJSON("/", j.Raw, nil)
+	return j.PollContext(context.Background())
+}
+
+func (j *Jenkins) PollContext(ctx context.Context) (int, error) {
+	resp, err := j.Requester.GetJSONContext(ctx, "/", j.Raw, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -500,22 +596,40 @@ func (j *Jenkins) Poll() (int, error) {
 }
 
 func (j *Jenkins) CreateCredentials(credentialData string) error {
+	return j.CreateCredentialsContext(context.Background(), credentialData)
+}
+
+func (j *Jenkins) CreateCredentialsContext(ctx context.Context, credentialData string) error {
 	credObject := Credentials{Jenkins: j, Raw: new(CredentialsResponse), Base: "/credentials/store/system/domain/_/"}
-	return credObject.Create(credentialData)
+	return credObject.CreateContext(ctx, credentialData)
 }
 
 func (j *Jenkins) RemoveCredentials(credentialsID string) error {
+	return j.RemoveCredentialsContext(context.Background(), credentialsID)
+}
+
+func (j *Jenkins) RemoveCredentialsContext(ctx context.Context, credentialsID string) error {
 	credObject := Credentials{Jenkins: j, Raw: new(CredentialsResponse), Base: "/credentials/store/system/domain/_/"}
-	return credObject.Remove(credentialsID)
+	return credObject.RemoveContext(ctx, credentialsID)
 }
 
 func (j *Jenkins) GetAllCredentials() ([]UserCredential, error) {
+	return j.GetAllCredentialsContext(context.Background())
+}
+
+func (j *Jenkins) GetAllCredentialsContext(ctx context.Context) ([]UserCredential, error) {
 	credObject := Credentials{Jenkins: j, Raw: new(CredentialsResponse), Base: "/credentials/store/system/domain/_/"}
-	return credObject.GetAll()
+	return credObject.GetAllContext(ctx)
 }
 
 // Creates a new Jenkins Instance
-// Optional parameters are: client, username, password
+// Optional parameters are: client, and either username and password or an Authenticator.
+// Other auth arguments are logged and ignored, so requests are sent without credentials.
+// e.g. CreateJenkins(nil, url, &APITokenAuth{Username: "admin", Token: token})
+// GET requests are retried with DefaultRetryPolicy; set Requester.Retry to change that.
+// Without a client, one is built by Requester.NewHTTPClient. To use CACert,
+// a client certificate or to skip verification, use CreateJenkinsWithTLS.
+// Messages are logged to stderr at LevelInfo; use SetLogger to change that.
 // After creating an instance call init method.
 func CreateJenkins(client *http.Client, base string, auth ...interface{}) *Jenkins {
 	j := &Jenkins{}
@@ -523,12 +637,52 @@ func CreateJenkins(client *http.Client, base string, auth ...interface{}) *Jenki
 		base = base[:len(base)-1]
 	}
 	j.Server = base
-	j.Requester = &Requester{Base: base, SslVerify: true, Client: client}
+	j.Logger = NewStdLogger(os.Stderr, LevelInfo)
+	j.Requester = &Requester{Base: base, SslVerify: true, Client: client, Retry: DefaultRetryPolicy(), Logger: j.Logger}
 	if j.Requester.Client == nil {
-		j.Requester.Client = http.DefaultClient
+		j.Requester.Client, _ = j.Requester.NewHTTPClient()
+	}
+	switch len(auth) {
+	case 0:
+		return j
+	case 1:
+		if authenticator, ok := auth[0].(Authenticator); ok {
+			j.Requester.Auth = authenticator
+			return j
+		}
+	case 2:
+		username, okUser := auth[0].(string)
+		password, okPassword := auth[1].(string)
+		if okUser && okPassword {
+			j.Requester.BasicAuth = &BasicAuth{Username: username, Password: password}
+			return j
+		}
 	}
-	if len(auth) == 2 {
-		j.Requester.BasicAuth = &BasicAuth{Username: auth[0].(string), Password: auth[1].(string)}
+	// Never log the values, they are likely credentials.
+	types := make([]string, len(auth))
+	for i, a := range auth {
+		types[i] = fmt.Sprintf("%T", a)
 	}
+	j.Logger.Error("CreateJenkins: ignoring unsupported auth arguments", "count", len(auth), "types", strings.Join(types, ","))
 	return j
 }
+
+// Creates a new Jenkins Instance whose client uses the given TLS settings.
+// Auth is passed on to CreateJenkins. An error is returned if the
+// certificates can't be loaded.
+func CreateJenkinsWithTLS(base string, opts *TLSOptions, auth ...interface{}) (*Jenkins, error) {
+	j := CreateJenkins(nil, base, auth...)
+	if opts != nil {
+		j.Requester.CACert = opts.CACert
+		j.Requester.ClientCert = opts.ClientCert
+		j.Requester.ClientKey = opts.ClientKey
+		j.Requester.ServerName = opts.ServerName
+		j.Requester.SslVerify = !opts.InsecureSkipVerify
+	}
+	client, err := j.Requester.NewHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	j.Requester.SetClient(client)
+	return j, nil
+}
@@ -2,6 +2,7 @@ package gojenkins
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -32,6 +33,16 @@ type JobBuildInfo struct {
 	ConsoleOutput string
 }
 
+// BuildSummary is a lightweight view of a build for listing.
+type BuildSummary struct {
+	Number    int64  `json:"number"`
+	Result    string `json:"result"`
+	Building  bool   `json:"building"`
+	Timestamp int64  `json:"timestamp"`
+	Duration  int64  `json:"duration"`
+	URL       string `json:"url"`
+}
+
 type InnerJob struct {
 	Name  string `json:"name"`
 	Url   string `json:"url"`
@@ -65,28 +76,29 @@ type JobResponse struct {
 		IconUrl       string `json:"iconUrl"`
 		Score         int64  `json:"score"`
 	} `json:"healthReport"`
-	InQueue               bool       `json:"inQueue"`
-	KeepDependencies      bool       `json:"keepDependencies"`
-	LastBuild             JobBuild   `json:"lastBuild"`
-	LastCompletedBuild    JobBuild   `json:"lastCompletedBuild"`
-	LastFailedBuild       JobBuild   `json:"lastFailedBuild"`
-	LastStableBuild       JobBuild   `json:"lastStableBuild"`
-	LastSuccessfulBuild   JobBuild   `json:"lastSuccessfulBuild"`
-	LastUnstableBuild     JobBuild   `json:"lastUnstableBuild"`
-	LastUnsuccessfulBuild JobBuild   `json:"lastUnsuccessfulBuild"`
-	Name                  string     `json:"name"`
-	SubJobs               []InnerJob `json:"jobs"`
-	NextBuildNumber       int64      `json:"nextBuildNumber"`
-	Property              []struct {
+	InQueue               bool     `json:"inQueue"`
+	KeepDependencies      bool     `json:"keepDependencies"`
+	LastBuild             JobBuild `json:"lastBuild"`
+	LastCompletedBuild    JobBuild `json:"lastCompletedBuild"`
+	LastFailedBuild       JobBuild `json:"lastFailedBuild"`
+	LastStableBuild       JobBuild `json:"lastStableBuild"`
+	LastSuccessfulBuild   JobBuild `json:"lastSuccessfulBuild"`
+	LastUnstableBuild     JobBuild `json:"lastUnstableBuild"`
+	LastUnsuccessfulBuild JobBuild `json:"lastUnsuccessfulBuild"`
+	Name                  string   `json:"name"`
+	// Deprecated: SubJobs is a copy of Jobs, kept for compatibility.
+	SubJobs         []InnerJob `json:"-"`
+	NextBuildNumber int64      `json:"nextBuildNumber"`
+	Property        []struct {
 		ParameterDefinitions []ParameterDefinition `json:"parameterDefinitions"`
 	} `json:"property"`
-	QueueItem        interface{} `json:"queueItem"`
-	Scm              struct{}    `json:"scm"`
-	UpstreamProjects []InnerJob  `json:"upstreamProjects"`
-	URL              string      `json:"url"`
-	Jobs             []InnerJob  `json:"jobs"`
-	PrimaryView      *ViewData   `json:"primaryView"`
-	Views            []ViewData  `json:"views"`
+	QueueItem        *QueueItem `json:"queueItem"`
+	Scm              struct{}   `json:"scm"`
+	UpstreamProjects []InnerJob `json:"upstreamProjects"`
+	URL              string     `json:"url"`
+	Jobs             []InnerJob `json:"jobs"`
+	PrimaryView      *ViewData  `json:"primaryView"`
+	Views            []ViewData `json:"views"`
 }
 
 func (j *Job) parentBase() string {
@@ -112,18 +124,19 @@ func (j *Job) GetDetails() *JobResponse {
 }
 
 func (j *Job) GetBuild(id int64) (*Build, error) {
+	return j.GetBuildContext(context.Background(), id)
+}
+
+func (j *Job) GetBuildContext(ctx context.Context, id int64) (*Build, error) {
 	build := Build{Jenkins: j.Jenkins, Job: j, Raw: new(BuildResponse), Depth: 1, Base: j.Base + "/" + strconv.FormatInt(id, 10)}
-	status, err := build.Poll()
+	_, err := build.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if status == 200 {
-		return &build, nil
-	}
-	return nil, errors.New(strconv.Itoa(status))
+	return &build, nil
 }
 
-func (j *Job) getBuildByType(buildType string) (*Build, error) {
+func (j *Job) getBuildByType(ctx context.Context, buildType string) (*Build, error) {
 	allowed := map[string]JobBuild{
 		"lastStableBuild":     j.Raw.LastStableBuild,
 		"lastSuccessfulBuild": j.Raw.LastSuccessfulBuild,
@@ -144,46 +157,71 @@ func (j *Job) getBuildByType(buildType string) (*Build, error) {
 		Job:     j,
 		Raw:     new(BuildResponse),
 		Base:    j.Base + "/" + number}
-	status, err := build.Poll()
+	_, err := build.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if status == 200 {
-		return &build, nil
-	}
-	return nil, errors.New(strconv.Itoa(status))
+	return &build, nil
 }
 
 func (j *Job) GetLastSuccessfulBuild() (*Build, error) {
-	return j.getBuildByType("lastSuccessfulBuild")
+	return j.GetLastSuccessfulBuildContext(context.Background())
+}
+
+func (j *Job) GetLastSuccessfulBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "lastSuccessfulBuild")
 }
 
 func (j *Job) GetFirstBuild() (*Build, error) {
-	return j.getBuildByType("firstBuild")
+	return j.GetFirstBuildContext(context.Background())
+}
+
+func (j *Job) GetFirstBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "firstBuild")
 }
 
 func (j *Job) GetLastBuild() (*Build, error) {
-	return j.getBuildByType("lastBuild")
+	return j.GetLastBuildContext(context.Background())
+}
+
+func (j *Job) GetLastBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "lastBuild")
 }
 
 func (j *Job) GetLastStableBuild() (*Build, error) {
-	return j.getBuildByType("lastStableBuild")
+	return j.GetLastStableBuildContext(context.Background())
+}
+
+func (j *Job) GetLastStableBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "lastStableBuild")
 }
 
 func (j *Job) GetLastFailedBuild() (*Build, error) {
-	return j.getBuildByType("lastFailedBuild")
+	return j.GetLastFailedBuildContext(context.Background())
+}
+
+func (j *Job) GetLastFailedBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "lastFailedBuild")
 }
 
 func (j *Job) GetLastCompletedBuild() (*Build, error) {
-	return j.getBuildByType("lastCompletedBuild")
+	return j.GetLastCompletedBuildContext(context.Background())
+}
+
+func (j *Job) GetLastCompletedBuildContext(ctx context.Context) (*Build, error) {
+	return j.getBuildByType(ctx, "lastCompletedBuild")
 }
 
 // Returns All Builds with Number and URL
 func (j *Job) GetAllBuildIds() ([]JobBuild, error) {
+	return j.GetAllBuildIdsContext(context.Background())
+}
+
+func (j *Job) GetAllBuildIdsContext(ctx context.Context) ([]JobBuild, error) {
 	var buildsResp struct {
 		Builds []JobBuild `json:"allBuilds"`
 	}
-	_, err := j.Jenkins.Requester.GetJSON(j.Base, &buildsResp, map[string]string{"tree": "allBuilds[number,url]"})
+	_, err := j.Jenkins.Requester.GetJSONContext(ctx, j.Base, &buildsResp, map[string]string{"tree": "allBuilds[number,url]"})
 	if err != nil {
 		return nil, err
 	}
@@ -192,10 +230,34 @@ func (j *Job) GetAllBuildIds() ([]JobBuild, error) {
 
 // Returns All Builds
 func (j *Job) GetAllBuildInfos() ([]JobBuildInfo, error) {
+	return j.GetAllBuildInfosContext(context.Background())
+}
+
+func (j *Job) GetAllBuildInfosContext(ctx context.Context) ([]JobBuildInfo, error) {
 	var buildsResp struct {
 		Builds []JobBuildInfo `json:"allBuilds"`
 	}
-	_, err := j.Jenkins.Requester.GetJSON(j.Base, &buildsResp, map[string]string{"tree": "allBuilds[fullDisplayName,id,url,number,timestamp,duration,result]"})
+	_, err := j.Jenkins.Requester.GetJSONContext(ctx, j.Base, &buildsResp, map[string]string{"tree": "allBuilds[fullDisplayName,id,url,number,timestamp,duration,result]"})
+	if err != nil {
+		return nil, err
+	}
+	return buildsResp.Builds, nil
+}
+
+// Returns the newest builds, at most limit of them (all if limit <= 0), fetching only the fields of BuildSummary.
+func (j *Job) GetBuildSummaries(limit int) ([]BuildSummary, error) {
+	return j.GetBuildSummariesContext(context.Background(), limit)
+}
+
+func (j *Job) GetBuildSummariesContext(ctx context.Context, limit int) ([]BuildSummary, error) {
+	var buildsResp struct {
+		Builds []BuildSummary `json:"allBuilds"`
+	}
+	tree := string(TreeOf(&buildsResp))
+	if limit > 0 {
+		tree += "{0," + strconv.Itoa(limit) + "}"
+	}
+	_, err := j.Jenkins.Requester.GetJSONContext(ctx, j.Base, &buildsResp, map[string]string{"tree": tree})
 	if err != nil {
 		return nil, err
 	}
@@ -203,7 +265,7 @@ func (j *Job) GetAllBuildInfos() ([]JobBuildInfo, error) {
 }
 
 func (j *Job) GetSubJobsMetadata() []InnerJob {
-	return j.Raw.SubJobs
+	return j.Raw.Jobs
 }
 
 func (j *Job) GetUpstreamJobsMetadata() []InnerJob {
@@ -215,9 +277,13 @@ func (j *Job) GetDownstreamJobsMetadata() []InnerJob {
 }
 
 func (j *Job) GetSubJobs() ([]*Job, error) {
-	jobs := make([]*Job, len(j.Raw.SubJobs))
-	for i, job := range j.Raw.SubJobs {
-		ji, err := j.Jenkins.GetSubJob(j.GetName(), job.Name)
+	return j.GetSubJobsContext(context.Background())
+}
+
+func (j *Job) GetSubJobsContext(ctx context.Context) ([]*Job, error) {
+	jobs := make([]*Job, len(j.Raw.Jobs))
+	for i, job := range j.Raw.Jobs {
+		ji, err := j.Jenkins.GetSubJobContext(ctx, j.GetName(), job.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -231,9 +297,13 @@ func (j *Job) GetInnerJobsMetadata() []InnerJob {
 }
 
 func (j *Job) GetUpstreamJobs() ([]*Job, error) {
+	return j.GetUpstreamJobsContext(context.Background())
+}
+
+func (j *Job) GetUpstreamJobsContext(ctx context.Context) ([]*Job, error) {
 	jobs := make([]*Job, len(j.Raw.UpstreamProjects))
 	for i, job := range j.Raw.UpstreamProjects {
-		ji, err := j.Jenkins.GetJob(job.Name)
+		ji, err := j.Jenkins.GetJobContext(ctx, job.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -243,9 +313,13 @@ func (j *Job) GetUpstreamJobs() ([]*Job, error) {
 }
 
 func (j *Job) GetDownstreamJobs() ([]*Job, error) {
+	return j.GetDownstreamJobsContext(context.Background())
+}
+
+func (j *Job) GetDownstreamJobsContext(ctx context.Context) ([]*Job, error) {
 	jobs := make([]*Job, len(j.Raw.DownstreamProjects))
 	for i, job := range j.Raw.DownstreamProjects {
-		ji, err := j.Jenkins.GetJob(job.Name)
+		ji, err := j.Jenkins.GetJobContext(ctx, job.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -255,21 +329,26 @@ func (j *Job) GetDownstreamJobs() ([]*Job, error) {
 }
 
 func (j *Job) GetInnerJob(id string) (*Job, error) {
+	return j.GetInnerJobContext(context.Background(), id)
+}
+
+func (j *Job) GetInnerJobContext(ctx context.Context, id string) (*Job, error) {
 	job := Job{Jenkins: j.Jenkins, Raw: new(JobResponse), Base: j.Base + "/job/" + id}
-	status, err := job.Poll()
+	_, err := job.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if status == 200 {
-		return &job, nil
-	}
-	return nil, errors.New(strconv.Itoa(status))
+	return &job, nil
 }
 
 func (j *Job) GetInnerJobs() ([]*Job, error) {
+	return j.GetInnerJobsContext(context.Background())
+}
+
+func (j *Job) GetInnerJobsContext(ctx context.Context) ([]*Job, error) {
 	jobs := make([]*Job, len(j.Raw.Jobs))
 	for i, job := range j.Raw.Jobs {
-		ji, err := j.GetInnerJob(job.Name)
+		ji, err := j.GetInnerJobContext(ctx, job.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -279,42 +358,49 @@ func (j *Job) GetInnerJobs() ([]*Job, error) {
 }
 
 func (j *Job) Enable() (bool, error) {
-	resp, err := j.Jenkins.Requester.Post(j.Base+"/enable", nil, nil, nil)
+	return j.EnableContext(context.Background())
+}
+
+func (j *Job) EnableContext(ctx context.Context) (bool, error) {
+	_, err := j.Jenkins.Requester.PostContext(ctx, j.Base+"/enable", nil, nil, nil)
 	if err != nil {
 		return false, err
 	}
-	if resp.StatusCode != 200 {
-		return false, errors.New(strconv.Itoa(resp.StatusCode))
-	}
 	return true, nil
 }
 
 func (j *Job) Disable() (bool, error) {
-	resp, err := j.Jenkins.Requester.Post(j.Base+"/disable", nil, nil, nil)
+	return j.DisableContext(context.Background())
+}
+
+func (j *Job) DisableContext(ctx context.Context) (bool, error) {
+	_, err := j.Jenkins.Requester.PostContext(ctx, j.Base+"/disable", nil, nil, nil)
 	if err != nil {
 		return false, err
 	}
-	if resp.StatusCode != 200 {
-		return false, errors.New(strconv.Itoa(resp.StatusCode))
-	}
 	return true, nil
 }
 
 func (j *Job) Delete() (bool, error) {
-	resp, err := j.Jenkins.Requester.Post(j.Base+"/doDelete", nil, nil, nil)
+	return j.DeleteContext(context.Background())
+}
+
+func (j *Job) DeleteContext(ctx context.Context) (bool, error) {
+	_, err := j.Jenkins.Requester.PostContext(ctx, j.Base+"/doDelete", nil, nil, nil)
 	if err != nil {
 		return false, err
 	}
-	if resp.StatusCode != 200 {
-		return false, errors.New(strconv.Itoa(resp.StatusCode))
-	}
 	return true, nil
 }
 
 func (j *Job) Rename(name string) (bool, error) {
+	return j.RenameContext(context.Background(), name)
+}
+
+func (j *Job) RenameContext(ctx context.Context, name string) (bool, error) {
 	data := url.Values{}
 	data.Set("newName", name)
-	_, err := j.Jenkins.Requester.Post(j.Base+"/doRename", bytes.NewBufferString(data.Encode()), nil, nil)
+	_, err := j.Jenkins.Requester.PostContext(ctx, j.Base+"/doRename", bytes.NewBufferString(data.Encode()), nil, nil)
 	if err != nil {
 		return false, err
 	}
@@ -322,57 +408,66 @@ func (j *Job) Rename(name string) (bool, error) {
 }
 
 func (j *Job) Create(config string, qr ...interface{}) (*Job, error) {
+	return j.CreateContext(context.Background(), config, qr...)
+}
+
+func (j *Job) CreateContext(ctx context.Context, config string, qr ...interface{}) (*Job, error) {
 	var querystring map[string]string
 	if len(qr) > 0 {
 		querystring = qr[0].(map[string]string)
 	}
-	resp, err := j.Jenkins.Requester.PostXML(j.parentBase()+"/createItem", config, j.Raw, querystring)
+	_, err := j.Jenkins.Requester.PostXMLContext(ctx, j.parentBase()+"/createItem", config, j.Raw, querystring)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode == 200 {
-		j.Poll()
-		return j, nil
+	if _, err := j.PollContext(ctx); err != nil {
+		return nil, err
 	}
-	return nil, errors.New(strconv.Itoa(resp.StatusCode))
+	return j, nil
 }
 
 func (j *Job) Copy(destinationName string) (*Job, error) {
+	return j.CopyContext(context.Background(), destinationName)
+}
+
+func (j *Job) CopyContext(ctx context.Context, destinationName string) (*Job, error) {
 	qr := map[string]string{"name": destinationName, "from": j.GetName(), "mode": "copy"}
-	resp, err := j.Jenkins.Requester.Post(j.parentBase()+"/createItem", nil, nil, qr)
+	_, err := j.Jenkins.Requester.PostContext(ctx, j.parentBase()+"/createItem", nil, nil, qr)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode == 200 {
-		newJob := &Job{Jenkins: j.Jenkins, Raw: new(JobResponse), Base: "/job/" + destinationName}
-		_, err := newJob.Poll()
-		if err != nil {
-			return nil, err
-		}
-		return newJob, nil
+	newJob := &Job{Jenkins: j.Jenkins, Raw: new(JobResponse), Base: "/job/" + destinationName}
+	_, err = newJob.PollContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New(strconv.Itoa(resp.StatusCode))
+	return newJob, nil
 }
 
 func (j *Job) UpdateConfig(config string) error {
+	return j.UpdateConfigContext(context.Background(), config)
+}
+
+func (j *Job) UpdateConfigContext(ctx context.Context, config string) error {
 
 	var querystring map[string]string
 
-	resp, err := j.Jenkins.Requester.PostXML(j.Base+"/config.xml", config, nil, querystring)
+	_, err := j.Jenkins.Requester.PostXMLContext(ctx, j.Base+"/config.xml", config, nil, querystring)
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode == 200 {
-		j.Poll()
-		return nil
-	}
-	return errors.New(strconv.Itoa(resp.StatusCode))
+	_, err = j.PollContext(ctx)
+	return err
 
 }
 
 func (j *Job) GetConfig() (string, error) {
+	return j.GetConfigContext(context.Background())
+}
+
+func (j *Job) GetConfigContext(ctx context.Context) (string, error) {
 	var data string
-	_, err := j.Jenkins.Requester.GetXML(j.Base+"/config.xml", &data, nil)
+	_, err := j.Jenkins.Requester.GetXMLContext(ctx, j.Base+"/config.xml", &data, nil)
 	if err != nil {
 		return "", err
 	}
@@ -380,7 +475,11 @@ func (j *Job) GetConfig() (string, error) {
 }
 
 func (j *Job) GetParameters() ([]ParameterDefinition, error) {
-	_, err := j.Poll()
+	return j.GetParametersContext(context.Background())
+}
+
+func (j *Job) GetParametersContext(ctx context.Context) ([]ParameterDefinition, error) {
+	_, err := j.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -392,46 +491,86 @@ func (j *Job) GetParameters() ([]ParameterDefinition, error) {
 }
 
 func (j *Job) IsQueued() (bool, error) {
-	if _, err := j.Poll(); err != nil {
+	return j.IsQueuedContext(context.Background())
+}
+
+func (j *Job) IsQueuedContext(ctx context.Context) (bool, error) {
+	if _, err := j.PollContext(ctx); err != nil {
 		return false, err
 	}
 	return j.Raw.InQueue, nil
 }
 
 func (j *Job) IsRunning() (bool, error) {
-	if _, err := j.Poll(); err != nil {
+	return j.IsRunningContext(context.Background())
+}
+
+func (j *Job) IsRunningContext(ctx context.Context) (bool, error) {
+	if _, err := j.PollContext(ctx); err != nil {
 		return false, err
 	}
-	lastBuild, err := j.GetLastBuild()
+	lastBuild, err := j.GetLastBuildContext(ctx)
 	if err != nil {
 		return false, err
 	}
-	return lastBuild.IsRunning(), nil
+	return lastBuild.IsRunningContext(ctx), nil
 }
 
 func (j *Job) IsEnabled() (bool, error) {
-	if _, err := j.Poll(); err != nil {
+	return j.IsEnabledContext(context.Background())
+}
+
+func (j *Job) IsEnabledContext(ctx context.Context) (bool, error) {
+	if _, err := j.PollContext(ctx); err != nil {
 		return false, err
 	}
 	return j.Raw.Color != "disabled", nil
 }
 
-func (j *Job) HasQueuedBuild() {
-	panic("Not Implemented yet")
+// Returns the queue item of the job if a build is waiting in the queue, e.g.
+// to show item.Why to the user.
+func (j *Job) HasQueuedBuild() (*QueueItem, bool, error) {
+	return j.HasQueuedBuildContext(context.Background())
 }
 
+func (j *Job) HasQueuedBuildContext(ctx context.Context) (*QueueItem, bool, error) {
+	if _, err := j.PollContext(ctx); err != nil {
+		return nil, false, err
+	}
+	return j.GetQueueItem(), j.Raw.QueueItem != nil, nil
+}
+
+// Returns the queue item from the last poll, or nil if no build was queued.
+func (j *Job) GetQueueItem() *QueueItem {
+	if j.Raw.QueueItem == nil {
+		return nil
+	}
+	j.Raw.QueueItem.Jenkins = j.Jenkins
+	return j.Raw.QueueItem
+}
+
+// Trigger a build and return the ID of its queue item, not a build number.
+// Use Trigger to get the build itself.
 func (j *Job) InvokeSimple(params map[string]string) (int64, error) {
-	isQueued, err := j.IsQueued()
+	return j.InvokeSimpleContext(context.Background(), params)
+}
+
+func (j *Job) InvokeSimpleContext(ctx context.Context, params map[string]string) (int64, error) {
+	isQueued, err := j.IsQueuedContext(ctx)
 	if err != nil {
 		return 0, err
 	}
 	if isQueued {
-		Error.Printf("%s is already running", j.GetName())
+		j.Jenkins.logger().Warn("job is already queued, not invoking it", "job", j.GetName())
 		return 0, nil
 	}
+	return j.invoke(ctx, params)
+}
 
+// invoke triggers a build and returns the ID of its queue item.
+func (j *Job) invoke(ctx context.Context, params map[string]string) (int64, error) {
 	endpoint := "/build"
-	parameters, err := j.GetParameters()
+	parameters, err := j.GetParametersContext(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -442,15 +581,11 @@ func (j *Job) InvokeSimple(params map[string]string) (int64, error) {
 	for k, v := range params {
 		data.Set(k, v)
 	}
-	resp, err := j.Jenkins.Requester.Post(j.Base+endpoint, bytes.NewBufferString(data.Encode()), nil, nil)
+	resp, err := j.Jenkins.Requester.PostContext(ctx, j.Base+endpoint, bytes.NewBufferString(data.Encode()), nil, nil)
 	if err != nil {
 		return 0, err
 	}
 
-	if resp.StatusCode != 200 && resp.StatusCode != 201 {
-		return 0, errors.New("Could not invoke job " + j.GetName())
-	}
-
 	location := resp.Header.Get("Location")
 	if location == "" {
 		return 0, errors.New("Don't have key \"Location\" in response of header")
@@ -469,16 +604,60 @@ func (j *Job) InvokeSimple(params map[string]string) (int64, error) {
 	return number, nil
 }
 
+// Trigger a build and wait until it leaves the queue. Unlike InvokeSimple,
+// a request for a job that is already queued is passed on to Jenkins, which
+// merges it with the queued one. Use Build.Wait to wait for the result.
+func (j *Job) Trigger(params map[string]string, opts *WaitOptions) (*Build, error) {
+	return j.TriggerContext(context.Background(), params, opts)
+}
+
+func (j *Job) TriggerContext(ctx context.Context, params map[string]string, opts *WaitOptions) (*Build, error) {
+	id, err := j.invoke(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	item, err := j.Jenkins.waitForQueueItem(ctx, id, opts)
+	if err != nil {
+		return nil, err
+	}
+	return j.GetBuildContext(ctx, item.Executable.Number)
+}
+
+// Trigger a build and block until it finishes. The timeout of opts covers
+// the time in the queue and the build itself.
+func (j *Job) TriggerAndWait(params map[string]string, opts *WaitOptions) (*Build, error) {
+	return j.TriggerAndWaitContext(context.Background(), params, opts)
+}
+
+func (j *Job) TriggerAndWaitContext(ctx context.Context, params map[string]string, opts *WaitOptions) (*Build, error) {
+	ctx, cancel, interval := opts.context(ctx)
+	defer cancel()
+	waitOpts := &WaitOptions{PollInterval: interval}
+
+	build, err := j.TriggerContext(ctx, params, waitOpts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := build.WaitContext(ctx, waitOpts); err != nil {
+		return build, err
+	}
+	return build, nil
+}
+
 func (j *Job) Invoke(files []string, skipIfRunning bool, params map[string]string, cause string, securityToken string) (bool, error) {
-	isQueued, err := j.IsQueued()
+	return j.InvokeContext(context.Background(), files, skipIfRunning, params, cause, securityToken)
+}
+
+func (j *Job) InvokeContext(ctx context.Context, files []string, skipIfRunning bool, params map[string]string, cause string, securityToken string) (bool, error) {
+	isQueued, err := j.IsQueuedContext(ctx)
 	if err != nil {
 		return false, err
 	}
 	if isQueued {
-		Error.Printf("%s is already running", j.GetName())
+		j.Jenkins.logger().Warn("job is already queued, not invoking it", "job", j.GetName())
 		return false, nil
 	}
-	isRunning, err := j.IsRunning()
+	isRunning, err := j.IsRunningContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -507,39 +686,51 @@ func (j *Job) Invoke(files []string, skipIfRunning bool, params map[string]strin
 
 	buildParams["json"] = string(makeJson(params))
 	b, _ := json.Marshal(buildParams)
-	resp, err := j.Jenkins.Requester.PostFiles(j.Base+base, bytes.NewBuffer(b), nil, reqParams, files)
+	_, err = j.Jenkins.Requester.PostFilesContext(ctx, j.Base+base, bytes.NewBuffer(b), nil, reqParams, files)
 	if err != nil {
 		return false, err
 	}
-	if resp.StatusCode == 200 || resp.StatusCode == 201 {
-		return true, nil
-	}
-	return false, errors.New(strconv.Itoa(resp.StatusCode))
+	return true, nil
 }
 
-func (j *Job) Poll() (int, error) {
-	response, err := j.Jenkins.Requester.GetJSON(j.Base, j.Raw, nil)
+// Poll for current data. Optional parameter - a Tree selecting the fields to fetch.
+func (j *Job) Poll(options ...interface{}) (int, error) {
+	return j.PollContext(context.Background(), options...)
+}
+
+func (j *Job) PollContext(ctx context.Context, options ...interface{}) (int, error) {
+	response, err := j.Jenkins.Requester.GetJSONContext(ctx, j.Base, j.Raw, applyTree(nil, options))
 	if err != nil {
 		return 0, err
 	}
+	j.Raw.SubJobs = j.Raw.Jobs
 	return response.StatusCode, nil
 }
 
 func (j *Job) History() ([]*History, error) {
-	resp, err := j.Jenkins.Requester.Get(j.Base+"/buildHistory/ajax", nil, nil)
+	return j.HistoryContext(context.Background())
+}
+
+func (j *Job) HistoryContext(ctx context.Context) ([]*History, error) {
+	var data string
+	_, err := j.Jenkins.Requester.GetContext(ctx, j.Base+"/buildHistory/ajax", &data, nil)
 	if err != nil {
 		return nil, err
 	}
-	return parseBuildHistory(resp.Body), nil
+	return parseBuildHistory(strings.NewReader(data)), nil
 }
 
 func (j *Job) GetBuildConsoleOutputWithTimestamp(id int64) string {
+	return j.GetBuildConsoleOutputWithTimestampContext(context.Background(), id)
+}
+
+func (j *Job) GetBuildConsoleOutputWithTimestampContext(ctx context.Context, id int64) string {
 	url := j.Base + fmt.Sprintf("/%d/timestamps", id)
 	queryMap := map[string]string{
 		"time":      "HH:mm:ss.S",
 		"appendLog": "",
 	}
 	var content string
-	j.Jenkins.Requester.GetXML(url, &content, queryMap)
+	j.Jenkins.Requester.GetXMLContext(ctx, url, &content, queryMap)
 	return content
 }
@@ -0,0 +1,23 @@
+package gojenkins
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJobSubJobs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"team","jobs":[{"name":"app","url":"http://jenkins/job/team/job/app/"}]}`))
+	}))
+	defer ts.Close()
+
+	job, err := CreateJenkins(nil, ts.URL).GetJob("team")
+	if err != nil {
+		t.Fatal("GetJob failed:", err)
+	}
+	if len(job.Raw.Jobs) != 1 || len(job.Raw.SubJobs) != 1 || job.Raw.SubJobs[0].Name != "app" {
+		t.Fatal("unexpected sub jobs:", job.Raw.Jobs, job.Raw.SubJobs)
+	}
+}
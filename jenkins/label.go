@@ -1,5 +1,7 @@
 package gojenkins
 
+import "context"
+
 type Label struct {
 	Raw     *LabelResponse
 	Jenkins *Jenkins
@@ -40,7 +42,11 @@ func (l *Label) GetNodes() []LabelNode {
 }
 
 func (l *Label) Poll() (int, error) {
-	response, err := l.Jenkins.Requester.GetJSON(l.Base, l.Raw, nil)
+	return l.PollContext(context.Background())
+}
+
+func (l *Label) PollContext(ctx context.Context) (int, error) {
+	response, err := l.Jenkins.Requester.GetJSONContext(ctx, l.Base, l.Raw, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,172 @@
+package gojenkins
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logger receives the client's log messages. keysAndValues are alternating
+// field names and values, e.g. logger.Warn("retrying", "url", u, "attempt", 2).
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+type stdLogger struct {
+	out   *log.Logger
+	level LogLevel
+}
+
+// NewStdLogger returns a Logger writing logfmt-style lines such as
+//
+//	2020/09/04 19:48:22 level=warn msg="local copy already exists, overwriting" path=/tmp/app.zip
+//
+// to w, dropping messages below level.
+func NewStdLogger(w io.Writer, level LogLevel) Logger {
+	return &stdLogger{out: log.New(w, "", log.LstdFlags), level: level}
+}
+
+func (l *stdLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log(LevelDebug, msg, keysAndValues)
+}
+
+func (l *stdLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log(LevelInfo, msg, keysAndValues)
+}
+
+func (l *stdLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log(LevelWarn, msg, keysAndValues)
+}
+
+func (l *stdLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.log(LevelError, msg, keysAndValues)
+}
+
+func (l *stdLogger) log(level LogLevel, msg string, keysAndValues []interface{}) {
+	if level < l.level {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "level=%s msg=%s", level, logfmtValue(msg))
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var v interface{} = "MISSING"
+		if i+1 < len(keysAndValues) {
+			v = keysAndValues[i+1]
+		}
+		fmt.Fprintf(&b, " %v=%s", keysAndValues[i], logfmtValue(v))
+	}
+	l.out.Output(3, b.String())
+}
+
+func logfmtValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " =\"") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// logFields pairs up keysAndValues. A trailing key without a value is
+// logged with the value "MISSING".
+func logFields(keysAndValues []interface{}) logrus.Fields {
+	fields := logrus.Fields{}
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := fmt.Sprint(keysAndValues[i])
+		if i+1 < len(keysAndValues) {
+			fields[key] = keysAndValues[i+1]
+		} else {
+			fields[key] = "MISSING"
+		}
+	}
+	return fields
+}
+
+type nopLogger struct{}
+
+// NopLogger returns a Logger that discards everything.
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+
+type logrusLogger struct {
+	entry logrus.FieldLogger
+}
+
+// NewLogrusLogger adapts a logrus logger, e.g. logrus.StandardLogger() as
+// used by the mgodb package.
+func NewLogrusLogger(l logrus.FieldLogger) Logger {
+	return logrusLogger{entry: l}
+}
+
+func (l logrusLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.entry.WithFields(logFields(keysAndValues)).Debug(msg)
+}
+
+func (l logrusLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.entry.WithFields(logFields(keysAndValues)).Info(msg)
+}
+
+func (l logrusLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.entry.WithFields(logFields(keysAndValues)).Warn(msg)
+}
+
+func (l logrusLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.entry.WithFields(logFields(keysAndValues)).Error(msg)
+}
+
+// SetLogger sets the Logger used by j and its Requester.
+func (j *Jenkins) SetLogger(logger Logger) *Jenkins {
+	j.Logger = logger
+	if j.Requester != nil {
+		j.Requester.Logger = logger
+	}
+	return j
+}
+
+func (j *Jenkins) logger() Logger {
+	if j == nil || j.Logger == nil {
+		return nopLogger{}
+	}
+	return j.Logger
+}
+
+func (r *Requester) logger() Logger {
+	if r.Logger == nil {
+		return nopLogger{}
+	}
+	return r.Logger
+}
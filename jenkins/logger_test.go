@@ -0,0 +1,60 @@
+package gojenkins
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(&buf, LevelWarn)
+
+	logger.Info("dropped")
+	logger.Warn("local copy already exists", "path", "/tmp/a b", "attempt", 2, "dangling")
+
+	out := buf.String()
+	if strings.Contains(out, "dropped") {
+		t.Fatal("message below level was logged:", out)
+	}
+	want := `level=warn msg="local copy already exists" path="/tmp/a b" attempt=2 dangling=MISSING`
+	if !strings.Contains(out, want) {
+		t.Fatalf("expected %q in %q", want, out)
+	}
+}
+
+func TestLogrusLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.Out = &buf
+	l.Formatter = &logrus.TextFormatter{DisableTimestamp: true}
+
+	NewLogrusLogger(l).Error("cannot open upload", "file", "a.txt")
+	if out := buf.String(); !strings.Contains(out, `level=error msg="cannot open upload" file=a.txt`) {
+		t.Fatal("unexpected logrus output:", out)
+	}
+}
+
+func TestLoggingWithoutInit(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	j := CreateJenkins(nil, ts.URL).SetLogger(NewStdLogger(&buf, LevelDebug))
+
+	// Upload errors are logged through the Requester.
+	_, err := j.Requester.PostFiles("/job/x/build", nil, nil, nil, []string{"/does/not/exist"})
+	if err == nil {
+		t.Fatal("expected error for missing upload")
+	}
+	if !strings.Contains(buf.String(), "file=/does/not/exist") {
+		t.Fatal("upload error was not logged:", buf.String())
+	}
+
+	// A zero Jenkins has no logger and must not panic.
+	(&Jenkins{}).logger().Warn("ignored")
+}
@@ -0,0 +1,76 @@
+package gojenkins
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestHandler sends req, the HTTP request built from ar, to Jenkins.
+type RequestHandler func(ar *APIRequest, req *http.Request) (*http.Response, error)
+
+// Middleware wraps a RequestHandler. It sees every request the Requester
+// sends, including each retry attempt and multipart uploads, after
+// authentication and crumb headers have been applied.
+type Middleware func(next RequestHandler) RequestHandler
+
+// Use appends middlewares to the chain. The first middleware registered is
+// the outermost one. Register middlewares before issuing requests.
+func (r *Requester) Use(middlewares ...Middleware) *Requester {
+	r.middlewares = append(r.middlewares, middlewares...)
+	return r
+}
+
+func (r *Requester) send(ar *APIRequest, req *http.Request) (*http.Response, error) {
+	handler := func(ar *APIRequest, req *http.Request) (*http.Response, error) {
+		return r.Client.Do(req)
+	}
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handler = r.middlewares[i](handler)
+	}
+	return handler(ar, req)
+}
+
+// LoggingMiddleware logs every request with its status and duration.
+// Mutating requests are logged at info level, reads at debug level and
+// failures at warn level.
+func LoggingMiddleware(logger Logger) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ar *APIRequest, req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			response, err := next(ar, req)
+			elapsed := time.Since(start)
+
+			if err != nil {
+				logger.Warn("jenkins request failed", "method", req.Method, "url", req.URL, "duration", elapsed, "error", err)
+				return response, err
+			}
+			log := logger.Debug
+			if response.StatusCode >= 400 {
+				log = logger.Warn
+			} else if req.Method != "GET" && req.Method != "HEAD" {
+				log = logger.Info
+			}
+			log("jenkins request", "method", req.Method, "url", req.URL, "status", response.StatusCode, "duration", elapsed)
+			return response, err
+		}
+	}
+}
+
+// TimingMiddleware reports the latency of every request to observe, keyed
+// by method and endpoint (the path without the Jenkins base URL, query
+// string or api suffix). status is 0 if no response was received.
+// e.g. requester.Use(TimingMiddleware(func(m, e string, s int, d time.Duration) { histogram.Observe(d) }))
+func TimingMiddleware(observe func(method string, endpoint string, status int, d time.Duration)) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ar *APIRequest, req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			response, err := next(ar, req)
+			status := 0
+			if response != nil {
+				status = response.StatusCode
+			}
+			observe(ar.Method, ar.Endpoint, status, time.Since(start))
+			return response, err
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package gojenkins
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareOrderAndHeaders(t *testing.T) {
+	var traceHeader string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceHeader = r.Header.Get("X-Trace-Id")
+	}))
+	defer ts.Close()
+
+	var calls []string
+	tag := func(name string) Middleware {
+		return func(next RequestHandler) RequestHandler {
+			return func(ar *APIRequest, req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				req.Header.Set("X-Trace-Id", "trace-"+name)
+				return next(ar, req)
+			}
+		}
+	}
+
+	j := CreateJenkins(nil, ts.URL)
+	j.Requester.Use(tag("outer"), tag("inner"))
+	if _, err := j.Poll(); err != nil {
+		t.Fatal("Poll failed:", err)
+	}
+	if strings.Join(calls, ",") != "outer,inner" {
+		t.Fatal("unexpected middleware order:", calls)
+	}
+	if traceHeader != "trace-inner" {
+		t.Fatal("header set by middleware not sent:", traceHeader)
+	}
+}
+
+func TestMiddlewareSeesUploads(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	file := filepath.Join(t.TempDir(), "upload.txt")
+	if err := ioutil.WriteFile(file, []byte("payload"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var endpoints, contentTypes []string
+	var statuses []int
+	j := CreateJenkins(nil, ts.URL)
+	j.Requester.Use(
+		func(next RequestHandler) RequestHandler {
+			return func(ar *APIRequest, req *http.Request) (*http.Response, error) {
+				contentTypes = append(contentTypes, req.Header.Get("Content-Type"))
+				return next(ar, req)
+			}
+		},
+		TimingMiddleware(func(method string, endpoint string, status int, d time.Duration) {
+			endpoints = append(endpoints, method+" "+endpoint)
+			statuses = append(statuses, status)
+		}),
+	)
+
+	if _, err := j.Requester.PostFiles("/job/x/build", nil, nil, nil, []string{file}); err != nil {
+		t.Fatal("PostFiles failed:", err)
+	}
+	if len(endpoints) != 2 || endpoints[0] != "GET /crumbIssuer/" || endpoints[1] != "POST /job/x/build" {
+		t.Fatal("unexpected endpoints:", endpoints)
+	}
+	if statuses[0] != http.StatusNotFound || statuses[1] != http.StatusOK {
+		t.Fatal("unexpected statuses:", statuses)
+	}
+	if !strings.HasPrefix(contentTypes[1], "multipart/form-data") {
+		t.Fatal("middleware did not see the multipart upload:", contentTypes[1])
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	j := CreateJenkins(nil, ts.URL)
+	j.Requester.Use(LoggingMiddleware(NewStdLogger(&buf, LevelInfo)))
+	if _, err := j.Requester.Post("/job/x/disable", nil, nil, nil); err != nil {
+		t.Fatal("Post failed:", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "level=warn") || !strings.Contains(out, "/crumbIssuer/api/json status=404") {
+		t.Fatal("failed request not logged as warning:", out)
+	}
+	if !strings.Contains(out, "level=info msg=\"jenkins request\" method=POST") {
+		t.Fatal("mutating request not logged at info level:", out)
+	}
+	if strings.Count(out, "\n") != 2 {
+		t.Fatal("unexpected log lines:", out)
+	}
+}
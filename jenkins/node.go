@@ -1,6 +1,9 @@
 package gojenkins
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 // Nodes
 
@@ -65,8 +68,23 @@ type NodeResponse struct {
 	TemporarilyOffline bool          `json:"temporarilyOffline"`
 }
 
+// NodeStatus is the subset of NodeResponse needed to tell whether a node can
+// take work.
+type NodeStatus struct {
+	DisplayName        string `json:"displayName"`
+	Idle               bool   `json:"idle"`
+	Offline            bool   `json:"offline"`
+	TemporarilyOffline bool   `json:"temporarilyOffline"`
+	OfflineCauseReason string `json:"offlineCauseReason"`
+	NumExecutors       int64  `json:"numExecutors"`
+}
+
 func (n *Node) Info() (*NodeResponse, error) {
-	_, err := n.Poll()
+	return n.InfoContext(context.Background())
+}
+
+func (n *Node) InfoContext(ctx context.Context) (*NodeResponse, error) {
+	_, err := n.PollContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -77,16 +95,38 @@ func (n *Node) GetName() string {
 	return n.Raw.DisplayName
 }
 
+// Get the node status without fetching executors and monitor data.
+func (n *Node) GetStatus() (*NodeStatus, error) {
+	return n.GetStatusContext(context.Background())
+}
+
+func (n *Node) GetStatusContext(ctx context.Context) (*NodeStatus, error) {
+	status := new(NodeStatus)
+	_, err := n.Jenkins.Requester.GetJSONTreeContext(ctx, n.Base, status, nil)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
 func (n *Node) Delete() (bool, error) {
-	resp, err := n.Jenkins.Requester.Post(n.Base+"/doDelete", nil, nil, nil)
+	return n.DeleteContext(context.Background())
+}
+
+func (n *Node) DeleteContext(ctx context.Context) (bool, error) {
+	_, err := n.Jenkins.Requester.PostContext(ctx, n.Base+"/doDelete", nil, nil, nil)
 	if err != nil {
 		return false, err
 	}
-	return resp.StatusCode == 200, nil
+	return true, nil
 }
 
 func (n *Node) IsOnline() (bool, error) {
-	_, err := n.Poll()
+	return n.IsOnlineContext(context.Background())
+}
+
+func (n *Node) IsOnlineContext(ctx context.Context) (bool, error) {
+	_, err := n.PollContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -94,7 +134,11 @@ func (n *Node) IsOnline() (bool, error) {
 }
 
 func (n *Node) IsTemporarilyOffline() (bool, error) {
-	_, err := n.Poll()
+	return n.IsTemporarilyOfflineContext(context.Background())
+}
+
+func (n *Node) IsTemporarilyOfflineContext(ctx context.Context) (bool, error) {
+	_, err := n.PollContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -102,7 +146,11 @@ func (n *Node) IsTemporarilyOffline() (bool, error) {
 }
 
 func (n *Node) IsIdle() (bool, error) {
-	_, err := n.Poll()
+	return n.IsIdleContext(context.Background())
+}
+
+func (n *Node) IsIdleContext(ctx context.Context) (bool, error) {
+	_, err := n.PollContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -110,7 +158,11 @@ func (n *Node) IsIdle() (bool, error) {
 }
 
 func (n *Node) IsJnlpAgent() (bool, error) {
-	_, err := n.Poll()
+	return n.IsJnlpAgentContext(context.Background())
+}
+
+func (n *Node) IsJnlpAgentContext(ctx context.Context) (bool, error) {
+	_, err := n.PollContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -118,7 +170,11 @@ func (n *Node) IsJnlpAgent() (bool, error) {
 }
 
 func (n *Node) SetOnline() (bool, error) {
-	_, err := n.Poll()
+	return n.SetOnlineContext(context.Background())
+}
+
+func (n *Node) SetOnlineContext(ctx context.Context) (bool, error) {
+	_, err := n.PollContext(ctx)
 
 	if err != nil {
 		return false, err
@@ -129,21 +185,29 @@ func (n *Node) SetOnline() (bool, error) {
 	}
 
 	if n.Raw.Offline && n.Raw.TemporarilyOffline {
-		return n.ToggleTemporarilyOffline()
+		return n.ToggleTemporarilyOfflineContext(ctx)
 	}
 
 	return true, nil
 }
 
 func (n *Node) SetOffline(options ...interface{}) (bool, error) {
+	return n.SetOfflineContext(context.Background(), options...)
+}
+
+func (n *Node) SetOfflineContext(ctx context.Context, options ...interface{}) (bool, error) {
 	if !n.Raw.Offline {
-		return n.ToggleTemporarilyOffline(options...)
+		return n.ToggleTemporarilyOfflineContext(ctx, options...)
 	}
 	return false, errors.New("Node already Offline")
 }
 
 func (n *Node) ToggleTemporarilyOffline(options ...interface{}) (bool, error) {
-	state_before, err := n.IsTemporarilyOffline()
+	return n.ToggleTemporarilyOfflineContext(context.Background(), options...)
+}
+
+func (n *Node) ToggleTemporarilyOfflineContext(ctx context.Context, options ...interface{}) (bool, error) {
+	state_before, err := n.IsTemporarilyOfflineContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -151,11 +215,11 @@ func (n *Node) ToggleTemporarilyOffline(options ...interface{}) (bool, error) {
 	if len(options) > 0 {
 		qr["offlineMessage"] = options[0].(string)
 	}
-	_, err = n.Jenkins.Requester.Post(n.Base+"/toggleOffline", nil, nil, qr)
+	_, err = n.Jenkins.Requester.PostContext(ctx, n.Base+"/toggleOffline", nil, nil, qr)
 	if err != nil {
 		return false, err
 	}
-	new_state, err := n.IsTemporarilyOffline()
+	new_state, err := n.IsTemporarilyOfflineContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -165,8 +229,13 @@ func (n *Node) ToggleTemporarilyOffline(options ...interface{}) (bool, error) {
 	return true, nil
 }
 
-func (n *Node) Poll() (int, error) {
-	response, err := n.Jenkins.Requester.GetJSON(n.Base, n.Raw, nil)
+// Poll for current data. Optional parameter - a Tree selecting the fields to fetch.
+func (n *Node) Poll(options ...interface{}) (int, error) {
+	return n.PollContext(context.Background(), options...)
+}
+
+func (n *Node) PollContext(ctx context.Context, options ...interface{}) (int, error) {
+	response, err := n.Jenkins.Requester.GetJSONContext(ctx, n.Base, n.Raw, applyTree(nil, options))
 	if err != nil {
 		return 0, err
 	}
@@ -174,11 +243,15 @@ func (n *Node) Poll() (int, error) {
 }
 
 func (n *Node) LaunchNodeBySSH() (int, error) {
+	return n.LaunchNodeBySSHContext(context.Background())
+}
+
+func (n *Node) LaunchNodeBySSHContext(ctx context.Context) (int, error) {
 	qr := map[string]string{
 		"json":   "",
 		"Submit": "Launch slave agent",
 	}
-	response, err := n.Jenkins.Requester.Post(n.Base+"/launchSlaveAgent", nil, nil, qr)
+	response, err := n.Jenkins.Requester.PostContext(ctx, n.Base+"/launchSlaveAgent", nil, nil, qr)
 	if err != nil {
 		return 0, err
 	}
@@ -186,12 +259,16 @@ func (n *Node) LaunchNodeBySSH() (int, error) {
 }
 
 func (n *Node) Disconnect() (int, error) {
+	return n.DisconnectContext(context.Background())
+}
+
+func (n *Node) DisconnectContext(ctx context.Context) (int, error) {
 	qr := map[string]string{
 		"offlineMessage": "",
 		"json":           makeJson(map[string]string{"offlineMessage": ""}),
 		"Submit":         "Yes",
 	}
-	response, err := n.Jenkins.Requester.Post(n.Base+"/doDisconnect", nil, nil, qr)
+	response, err := n.Jenkins.Requester.PostContext(ctx, n.Base+"/doDisconnect", nil, nil, qr)
 	if err != nil {
 		return 0, err
 	}
@@ -199,17 +276,21 @@ func (n *Node) Disconnect() (int, error) {
 }
 
 func (n *Node) GetLogText() (string, error) {
+	return n.GetLogTextContext(context.Background())
+}
+
+func (n *Node) GetLogTextContext(ctx context.Context) (string, error) {
 	var log string
 
-	_, err := n.Jenkins.Requester.Post(n.Base+"/log", nil, nil, nil)
+	_, err := n.Jenkins.Requester.PostContext(ctx, n.Base+"/log", nil, nil, nil)
 	if err != nil {
 		return "", err
 	}
 
 	qr := map[string]string{"start": "0"}
-	_, err = n.Jenkins.Requester.GetJSON(n.Base+"/logText/progressiveHtml/", &log, qr)
+	_, err = n.Jenkins.Requester.GetJSONContext(ctx, n.Base+"/logText/progressiveHtml/", &log, qr)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 
 	return log, nil
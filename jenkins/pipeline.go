@@ -0,0 +1,253 @@
+package gojenkins
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Pipeline builds are described by the Pipeline Stage View plugin's REST
+// API below <build>/wfapi. More about it here:
+// https://github.com/jenkinsci/pipeline-stage-view-plugin/tree/master/rest-api
+
+// PipelineNode holds the fields shared by Pipeline stages and steps.
+// Status is one of the PIPELINE_STATUS_* constants.
+type PipelineNode struct {
+	ID                  string         `json:"id"`
+	Name                string         `json:"name"`
+	ExecNode            string         `json:"execNode"`
+	Status              string         `json:"status"`
+	StartTimeMillis     int64          `json:"startTimeMillis"`
+	DurationMillis      int64          `json:"durationMillis"`
+	PauseDurationMillis int64          `json:"pauseDurationMillis"`
+	Error               *PipelineError `json:"error"`
+}
+
+type PipelineError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+type PipelineRun struct {
+	ID                  string          `json:"id"`
+	Name                string          `json:"name"`
+	Status              string          `json:"status"`
+	StartTimeMillis     int64           `json:"startTimeMillis"`
+	EndTimeMillis       int64           `json:"endTimeMillis"`
+	DurationMillis      int64           `json:"durationMillis"`
+	QueueDurationMillis int64           `json:"queueDurationMillis"`
+	PauseDurationMillis int64           `json:"pauseDurationMillis"`
+	Stages              []PipelineStage `json:"stages"`
+}
+
+// PipelineStage is a stage of a run. Steps is only filled in by
+// Build.GetPipelineStage.
+type PipelineStage struct {
+	PipelineNode
+	Steps []PipelineStep `json:"stageFlowNodes"`
+}
+
+type PipelineStep struct {
+	PipelineNode
+	ParameterDescription string   `json:"parameterDescription"`
+	ParentNodes          []string `json:"parentNodes"`
+}
+
+// PipelineNodeLog is the log written by a single step.
+type PipelineNodeLog struct {
+	NodeID     string `json:"nodeId"`
+	NodeStatus string `json:"nodeStatus"`
+	Length     int64  `json:"length"`
+	HasMore    bool   `json:"hasMore"`
+	Text       string `json:"text"`
+	ConsoleURL string `json:"consoleUrl"`
+}
+
+func (n PipelineNode) StartTime() time.Time {
+	return time.Unix(0, n.StartTimeMillis*int64(time.Millisecond))
+}
+
+func (n PipelineNode) Duration() time.Duration {
+	return time.Duration(n.DurationMillis) * time.Millisecond
+}
+
+// Returns the first stage that failed, or nil.
+func (r *PipelineRun) FailedStage() *PipelineStage {
+	for i := range r.Stages {
+		if r.Stages[i].Status == PIPELINE_STATUS_FAILED {
+			return &r.Stages[i]
+		}
+	}
+	return nil
+}
+
+// Get the stages of a Pipeline build with their status and timing.
+func (b *Build) GetPipelineRun() (*PipelineRun, error) {
+	return b.GetPipelineRunContext(context.Background())
+}
+
+func (b *Build) GetPipelineRunContext(ctx context.Context) (*PipelineRun, error) {
+	run := new(PipelineRun)
+	_, err := b.Jenkins.Requester.GetContext(ctx, b.Base+"/wfapi/describe", run, nil)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Get a stage of a Pipeline build including its steps.
+func (b *Build) GetPipelineStage(id string) (*PipelineStage, error) {
+	return b.GetPipelineStageContext(context.Background(), id)
+}
+
+func (b *Build) GetPipelineStageContext(ctx context.Context, id string) (*PipelineStage, error) {
+	stage := new(PipelineStage)
+	_, err := b.Jenkins.Requester.GetContext(ctx, b.pipelineNodeBase(id)+"/wfapi/describe", stage, nil)
+	if err != nil {
+		return nil, err
+	}
+	return stage, nil
+}
+
+// Get the log of a single Pipeline step.
+func (b *Build) GetPipelineNodeLog(id string) (*PipelineNodeLog, error) {
+	return b.GetPipelineNodeLogContext(context.Background(), id)
+}
+
+func (b *Build) GetPipelineNodeLogContext(ctx context.Context, id string) (*PipelineNodeLog, error) {
+	log := new(PipelineNodeLog)
+	_, err := b.Jenkins.Requester.GetContext(ctx, b.pipelineNodeBase(id)+"/wfapi/log", log, nil)
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// Get the log of a stage, i.e. the logs of its steps in order. Step logs
+// longer than the API returns are cut off by Jenkins; their full text is
+// available from PipelineNodeLog.ConsoleURL.
+func (b *Build) GetPipelineStageLog(id string) (string, error) {
+	return b.GetPipelineStageLogContext(context.Background(), id)
+}
+
+func (b *Build) GetPipelineStageLogContext(ctx context.Context, id string) (string, error) {
+	stage, err := b.GetPipelineStageContext(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	var text strings.Builder
+	for _, step := range stage.Steps {
+		log, err := b.GetPipelineNodeLogContext(ctx, step.ID)
+		if err != nil {
+			return "", err
+		}
+		text.WriteString(log.Text)
+	}
+	return text.String(), nil
+}
+
+func (b *Build) pipelineNodeBase(id string) string {
+	return b.Base + "/execution/node/" + id
+}
+
+// PipelineInputAction is an input step a Pipeline build waits on.
+type PipelineInputAction struct {
+	ID                  string                   `json:"id"`
+	Message             string                   `json:"message"`
+	ProceedText         string                   `json:"proceedText"`
+	Inputs              []PipelineInputParameter `json:"inputs"`
+	ProceedURL          string                   `json:"proceedUrl"`
+	AbortURL            string                   `json:"abortUrl"`
+	RedirectApprovalURL string                   `json:"redirectApprovalUrl"`
+}
+
+// PipelineInputParameter is a parameter requested by an input step, e.g.
+// of Type "StringParameterDefinition".
+type PipelineInputParameter struct {
+	Type        string                 `json:"type"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Definition  map[string]interface{} `json:"definition"`
+}
+
+// Get the input steps the build is waiting on.
+func (b *Build) GetPendingInputActions() ([]PipelineInputAction, error) {
+	return b.GetPendingInputActionsContext(context.Background())
+}
+
+func (b *Build) GetPendingInputActionsContext(ctx context.Context) ([]PipelineInputAction, error) {
+	var actions []PipelineInputAction
+	_, err := b.Jenkins.Requester.GetContext(ctx, b.Base+"/wfapi/pendingInputActions", &actions, nil)
+	if err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// Approve the input step with the given id. params maps parameter names to
+// values of the matching type, e.g. a bool for a BooleanParameterDefinition.
+// Jenkins records the authenticated user as the approver.
+func (b *Build) ProceedInput(id string, params map[string]interface{}) error {
+	return b.ProceedInputContext(context.Background(), id, params)
+}
+
+func (b *Build) ProceedInputContext(ctx context.Context, id string, params map[string]interface{}) error {
+	if len(params) == 0 {
+		_, err := b.Jenkins.Requester.PostContext(ctx, b.inputBase(id)+"/proceedEmpty", nil, nil, nil)
+		if err != nil {
+			return err
+		}
+		b.Jenkins.logger().Info("pipeline input approved", "build", b.Base, "input", id)
+		return nil
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	type parameterValue struct {
+		Name  string      `json:"name"`
+		Value interface{} `json:"value"`
+	}
+	form := struct {
+		Parameter []parameterValue `json:"parameter"`
+	}{}
+	for _, name := range names {
+		form.Parameter = append(form.Parameter, parameterValue{Name: name, Value: params[name]})
+	}
+	formJSON, err := json.Marshal(form)
+	if err != nil {
+		return err
+	}
+
+	data := url.Values{}
+	data.Set("json", string(formJSON))
+	_, err = b.Jenkins.Requester.PostContext(ctx, b.inputBase(id)+"/proceed", strings.NewReader(data.Encode()), nil, nil)
+	if err != nil {
+		return err
+	}
+	b.Jenkins.logger().Info("pipeline input approved", "build", b.Base, "input", id, "parameters", strings.Join(names, ","))
+	return nil
+}
+
+// Reject the input step with the given id, which aborts the build.
+func (b *Build) AbortInput(id string) error {
+	return b.AbortInputContext(context.Background(), id)
+}
+
+func (b *Build) AbortInputContext(ctx context.Context, id string) error {
+	_, err := b.Jenkins.Requester.PostContext(ctx, b.inputBase(id)+"/abort", nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	b.Jenkins.logger().Info("pipeline input aborted", "build", b.Base, "input", id)
+	return nil
+}
+
+func (b *Build) inputBase(id string) string {
+	return b.Base + "/input/" + url.PathEscape(id)
+}
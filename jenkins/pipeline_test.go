@@ -0,0 +1,116 @@
+package gojenkins
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func pipelineServer() *httptest.Server {
+	responses := map[string]string{
+		"/job/app/7/wfapi/describe/": `{"id":"7","name":"#7","status":"FAILED","startTimeMillis":1600000000000,"durationMillis":65000,
+			"stages":[
+				{"id":"6","name":"Build","status":"SUCCESS","startTimeMillis":1600000001000,"durationMillis":30000},
+				{"id":"15","name":"Test","status":"FAILED","startTimeMillis":1600000031000,"durationMillis":34000,
+				 "error":{"message":"script returned exit code 1","type":"hudson.AbortException"}}]}`,
+		"/job/app/7/execution/node/15/wfapi/describe/": `{"id":"15","name":"Test","status":"FAILED",
+			"stageFlowNodes":[
+				{"id":"16","name":"Shell Script","status":"SUCCESS","parameterDescription":"make deps","parentNodes":["15"]},
+				{"id":"17","name":"Shell Script","status":"FAILED","parameterDescription":"make test","parentNodes":["16"]}]}`,
+		"/job/app/7/execution/node/16/wfapi/log/": `{"nodeId":"16","nodeStatus":"SUCCESS","length":9,"hasMore":false,"text":"deps ok\n"}`,
+		"/job/app/7/execution/node/17/wfapi/log/": `{"nodeId":"17","nodeStatus":"FAILED","length":12,"hasMore":false,"text":"FAIL: TestX\n"}`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+}
+
+func TestPipelineRun(t *testing.T) {
+	ts := pipelineServer()
+	defer ts.Close()
+
+	b := &Build{Jenkins: CreateJenkins(nil, ts.URL), Base: "/job/app/7", Raw: new(BuildResponse)}
+	run, err := b.GetPipelineRun()
+	if err != nil {
+		t.Fatal("GetPipelineRun failed:", err)
+	}
+	failed := run.FailedStage()
+	if len(run.Stages) != 2 || failed == nil || failed.Name != "Test" {
+		t.Fatal("unexpected stages:", run.Stages)
+	}
+	if failed.Duration() != 34*time.Second || failed.StartTime().Unix() != 1600000031 || failed.Error.Message != "script returned exit code 1" {
+		t.Fatal("unexpected stage details:", failed)
+	}
+
+	stage, err := b.GetPipelineStage(failed.ID)
+	if err != nil {
+		t.Fatal("GetPipelineStage failed:", err)
+	}
+	if len(stage.Steps) != 2 || stage.Steps[1].ParameterDescription != "make test" || stage.Steps[1].Status != PIPELINE_STATUS_FAILED {
+		t.Fatal("unexpected steps:", stage.Steps)
+	}
+
+	log, err := b.GetPipelineStageLog(failed.ID)
+	if err != nil {
+		t.Fatal("GetPipelineStageLog failed:", err)
+	}
+	if log != "deps ok\nFAIL: TestX\n" {
+		t.Fatal("unexpected stage log:", log)
+	}
+}
+
+func TestPipelineInput(t *testing.T) {
+	var posts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/job/app/8/wfapi/pendingInputActions/":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":"Deploy","message":"Deploy to production?","proceedText":"Ship it",
+				"inputs":[{"type":"BooleanParameterDefinition","name":"MIGRATE","description":"Run migrations"},
+				          {"type":"StringParameterDefinition","name":"TARGET"}]}]`))
+		case "/job/app/8/input/Deploy/proceed", "/job/app/8/input/Deploy/proceedEmpty", "/job/app/8/input/Deploy/abort":
+			if r.Method != "POST" {
+				t.Error("unexpected method:", r.Method)
+			}
+			r.ParseForm()
+			posts = append(posts, r.URL.Path+" "+r.PostForm.Get("json"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	b := &Build{Jenkins: CreateJenkins(nil, ts.URL), Base: "/job/app/8", Raw: new(BuildResponse)}
+	actions, err := b.GetPendingInputActions()
+	if err != nil {
+		t.Fatal("GetPendingInputActions failed:", err)
+	}
+	if len(actions) != 1 || actions[0].Message != "Deploy to production?" || actions[0].Inputs[0].Type != "BooleanParameterDefinition" {
+		t.Fatal("unexpected input actions:", actions)
+	}
+
+	if err := b.ProceedInput("Deploy", map[string]interface{}{"TARGET": "eu-west", "MIGRATE": true}); err != nil {
+		t.Fatal("ProceedInput failed:", err)
+	}
+	if err := b.ProceedInput("Deploy", nil); err != nil {
+		t.Fatal("ProceedInput without parameters failed:", err)
+	}
+	if err := b.AbortInput("Deploy"); err != nil {
+		t.Fatal("AbortInput failed:", err)
+	}
+	want := []string{
+		`/job/app/8/input/Deploy/proceed {"parameter":[{"name":"MIGRATE","value":true},{"name":"TARGET","value":"eu-west"}]}`,
+		"/job/app/8/input/Deploy/proceedEmpty ",
+		"/job/app/8/input/Deploy/abort ",
+	}
+	if len(posts) != 3 || posts[0] != want[0] || posts[1] != want[1] || posts[2] != want[2] {
+		t.Fatal("unexpected requests:", posts)
+	}
+}
@@ -1,6 +1,7 @@
 package gojenkins
 
 import (
+	"context"
 	"strconv"
 )
 
@@ -50,10 +51,14 @@ func (p *Plugins) Contains(name string) *Plugin {
 }
 
 func (p *Plugins) Poll() (int, error) {
+	return p.PollContext(context.Background())
+}
+
+func (p *Plugins) PollContext(ctx context.Context) (int, error) {
 	qr := map[string]string{
 		"depth": strconv.Itoa(p.Depth),
 	}
-	response, err := p.Jenkins.Requester.GetJSON(p.Base, p.Raw, qr)
+	response, err := p.Jenkins.Requester.GetJSONContext(ctx, p.Base, p.Raw, qr)
 	if err != nil {
 		return 0, err
 	}
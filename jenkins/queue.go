@@ -1,30 +1,57 @@
 package gojenkins
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// ErrQueueItemCancelled is returned when waiting for a queue item that was
+// cancelled before it started building.
+var ErrQueueItemCancelled = errors.New("queue item was cancelled")
+
+// Queue is a snapshot of the build queue. Poll replaces the snapshot and
+// may run while other goroutines read tasks through the Queue's methods.
 type Queue struct {
 	Jenkins *Jenkins
 	Raw     *queueResponse
 	Base    string
+
+	mu sync.RWMutex
+}
+
+// CancelResult is the outcome of cancelling one task of a bulk cancel.
+type CancelResult struct {
+	Task *Task
+	Err  error
 }
 
 type queueResponse struct {
-	Items []taskResponse
+	Items []QueueItem
 }
 
 type Task struct {
-	Raw     *taskResponse
+	Raw     *QueueItem
 	Jenkins *Jenkins
 	Queue   *Queue
 }
 
-type taskResponse struct {
+// QueueItem is a queued build request, as listed by /queue or returned by
+// /queue/item/<id>. Jenkins keeps an item for a few minutes after it left
+// the queue; Executable is set once it started building.
+type QueueItem struct {
+	Jenkins *Jenkins `json:"-"`
+
 	Actions                    []generalAction `json:"actions"`
 	Blocked                    bool            `json:"blocked"`
 	Buildable                  bool            `json:"buildable"`
 	BuildableStartMilliseconds int64           `json:"buildableStartMilliseconds"`
+	Cancelled                  bool            `json:"cancelled"`
 	ID                         int64           `json:"id"`
 	InQueueSince               int64           `json:"inQueueSince"`
 	Params                     string          `json:"params"`
@@ -35,60 +62,142 @@ type taskResponse struct {
 		Name  string `json:"name"`
 		URL   string `json:"url"`
 	} `json:"task"`
-	URL string `json:"url"`
-	Why string `json:"why"`
+	URL        string           `json:"url"`
+	Why        string           `json:"why"`
+	Executable *QueueExecutable `json:"executable"`
+}
+
+// QueueExecutable is the build started for a queue item.
+type QueueExecutable struct {
+	Number int64  `json:"number"`
+	URL    string `json:"url"`
+}
+
+// WaitOptions controls how long to wait for a queue item or build.
+type WaitOptions struct {
+	// PollInterval is the delay between two polls.
+	PollInterval time.Duration
+	// Timeout bounds the whole wait. Zero means no limit besides the
+	// context.
+	Timeout time.Duration
+}
+
+// Delay between two polls while waiting, by default.
+const defaultWaitPollInterval = 2 * time.Second
+
+// context returns ctx bounded by the timeout of o, and the poll interval.
+func (o *WaitOptions) context(ctx context.Context) (context.Context, context.CancelFunc, time.Duration) {
+	var opts WaitOptions
+	if o != nil {
+		opts = *o
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultWaitPollInterval
+	}
+	if opts.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		return ctx, cancel, opts.PollInterval
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, cancel, opts.PollInterval
 }
 
 type generalAction struct {
-	Causes     []map[string]interface{}
+	Causes     []Cause
 	Parameters []parameter
 }
 
 func (q *Queue) Tasks() []*Task {
-	tasks := make([]*Task, len(q.Raw.Items))
-	for i, t := range q.Raw.Items {
-		tasks[i] = &Task{Jenkins: q.Jenkins, Queue: q, Raw: &t}
-	}
-	return tasks
+	return q.tasks(func(*Task) bool { return true })
 }
 
 func (q *Queue) GetTaskById(id int64) *Task {
-	for _, t := range q.Raw.Items {
-		if t.ID == id {
-			return &Task{Jenkins: q.Jenkins, Queue: q, Raw: &t}
-		}
+	tasks := q.tasks(func(t *Task) bool { return t.Raw.ID == id })
+	if len(tasks) == 0 {
+		return nil
 	}
-	return nil
+	return tasks[0]
 }
 
 func (q *Queue) GetTasksForJob(name string) []*Task {
+	return q.tasks(func(t *Task) bool { return t.Raw.Task.Name == name })
+}
+
+// tasks returns the tasks of the snapshot selected by match. Every task
+// holds its own copy of the item.
+func (q *Queue) tasks(match func(*Task) bool) []*Task {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
 	tasks := make([]*Task, 0)
-	for _, t := range q.Raw.Items {
-		if t.Task.Name == name {
-			tasks = append(tasks, &Task{Jenkins: q.Jenkins, Queue: q, Raw: &t})
+	for i := range q.Raw.Items {
+		item := q.Raw.Items[i]
+		item.Jenkins = q.Jenkins
+		t := &Task{Jenkins: q.Jenkins, Queue: q, Raw: &item}
+		if match(t) {
+			tasks = append(tasks, t)
 		}
 	}
 	return tasks
 }
 
 func (q *Queue) CancelTask(id int64) (bool, error) {
+	return q.CancelTaskContext(context.Background(), id)
+}
+
+func (q *Queue) CancelTaskContext(ctx context.Context, id int64) (bool, error) {
 	task := q.GetTaskById(id)
-	return task.Cancel()
+	if task == nil {
+		return false, fmt.Errorf("no task %d in the queue", id)
+	}
+	return task.CancelContext(ctx)
+}
+
+// Cancel every queued build of the job, returning the outcome per task.
+func (q *Queue) CancelTasksForJob(name string) []CancelResult {
+	return q.CancelTasksForJobContext(context.Background(), name)
+}
+
+func (q *Queue) CancelTasksForJobContext(ctx context.Context, name string) []CancelResult {
+	return q.CancelTasksFuncContext(ctx, func(t *Task) bool { return t.Raw.Task.Name == name })
+}
+
+// Cancel the tasks selected by match, returning the outcome per task.
+// e.g. q.CancelTasksFunc(func(t *Task) bool { return t.Raw.Stuck })
+func (q *Queue) CancelTasksFunc(match func(*Task) bool) []CancelResult {
+	return q.CancelTasksFuncContext(context.Background(), match)
+}
+
+func (q *Queue) CancelTasksFuncContext(ctx context.Context, match func(*Task) bool) []CancelResult {
+	tasks := q.tasks(match)
+	results := make([]CancelResult, len(tasks))
+	for i, t := range tasks {
+		_, err := t.CancelContext(ctx)
+		results[i] = CancelResult{Task: t, Err: err}
+	}
+	return results
 }
 
 func (t *Task) Cancel() (bool, error) {
+	return t.CancelContext(context.Background())
+}
+
+func (t *Task) CancelContext(ctx context.Context) (bool, error) {
 	qr := map[string]string{
 		"id": strconv.FormatInt(t.Raw.ID, 10),
 	}
-	response, err := t.Jenkins.Requester.Post(t.Jenkins.GetQueueUrl()+"/cancelItem", nil, t.Raw, qr)
+	_, err := t.Jenkins.Requester.PostContext(ctx, t.Jenkins.GetQueueUrl()+"/cancelItem", nil, nil, qr)
 	if err != nil {
 		return false, err
 	}
-	return response.StatusCode == 200, nil
+	return true, nil
 }
 
 func (t *Task) GetJob() (*Job, error) {
-	return t.Jenkins.GetJob(t.Raw.Task.Name)
+	return t.GetJobContext(context.Background())
+}
+
+func (t *Task) GetJobContext(ctx context.Context) (*Job, error) {
+	return t.Jenkins.GetJobContext(ctx, t.Raw.Task.Name)
 }
 
 func (t *Task) GetWhy() string {
@@ -96,7 +205,58 @@ func (t *Task) GetWhy() string {
 }
 
 func (t *Task) GetParameters() []parameter {
-	for _, a := range t.Raw.Actions {
+	return t.Raw.GetParameters()
+}
+
+func (t *Task) GetCauses() []Cause {
+	return t.Raw.GetCauses()
+}
+
+func (q *Queue) Poll() (int, error) {
+	return q.PollContext(context.Background())
+}
+
+func (q *Queue) PollContext(ctx context.Context) (int, error) {
+	raw := new(queueResponse)
+	response, err := q.Jenkins.Requester.GetJSONContext(ctx, q.Base, raw, nil)
+	if err != nil {
+		return 0, err
+	}
+	q.mu.Lock()
+	q.Raw = raw
+	q.mu.Unlock()
+	return response.StatusCode, nil
+}
+
+// Refresh the item from /queue/item/<id>.
+func (i *QueueItem) Poll() (int, error) {
+	return i.PollContext(context.Background())
+}
+
+func (i *QueueItem) PollContext(ctx context.Context) (int, error) {
+	response, err := i.Jenkins.Requester.GetJSONContext(ctx, i.Jenkins.GetQueueUrl()+"/item/"+strconv.FormatInt(i.ID, 10), i, nil)
+	if err != nil {
+		return 0, err
+	}
+	return response.StatusCode, nil
+}
+
+// Time at which the item entered the queue.
+func (i *QueueItem) QueuedAt() time.Time {
+	return time.Unix(0, i.InQueueSince*int64(time.Millisecond))
+}
+
+// Time since which the item is ready to run and waits for an executor, or
+// the zero time if it isn't buildable.
+func (i *QueueItem) BuildableSince() time.Time {
+	if !i.Buildable && !i.Stuck || i.BuildableStartMilliseconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, i.BuildableStartMilliseconds*int64(time.Millisecond))
+}
+
+func (i *QueueItem) GetParameters() []parameter {
+	for _, a := range i.Actions {
 		if a.Parameters != nil {
 			return a.Parameters
 		}
@@ -104,8 +264,8 @@ func (t *Task) GetParameters() []parameter {
 	return nil
 }
 
-func (t *Task) GetCauses() []map[string]interface{} {
-	for _, a := range t.Raw.Actions {
+func (i *QueueItem) GetCauses() []Cause {
+	for _, a := range i.Actions {
 		if a.Causes != nil {
 			return a.Causes
 		}
@@ -113,10 +273,77 @@ func (t *Task) GetCauses() []map[string]interface{} {
 	return nil
 }
 
-func (q *Queue) Poll() (int, error) {
-	response, err := q.Jenkins.Requester.GetJSON(q.Base, q.Raw, nil)
+// Get the build started for the item. Fails if it has not started yet.
+func (i *QueueItem) GetBuild() (*Build, error) {
+	return i.GetBuildContext(context.Background())
+}
+
+func (i *QueueItem) GetBuildContext(ctx context.Context) (*Build, error) {
+	if i.Executable == nil {
+		return nil, fmt.Errorf("queue item %d has not started building", i.ID)
+	}
+	job := &Job{Jenkins: i.Jenkins, Raw: new(JobResponse), Base: i.Jenkins.pathOf(i.Task.URL)}
+	if _, err := job.PollContext(ctx); err != nil {
+		return nil, err
+	}
+	return job.GetBuildContext(ctx, i.Executable.Number)
+}
+
+func (j *Jenkins) GetQueueItem(id int64) (*QueueItem, error) {
+	return j.GetQueueItemContext(context.Background(), id)
+}
+
+func (j *Jenkins) GetQueueItemContext(ctx context.Context, id int64) (*QueueItem, error) {
+	item := &QueueItem{Jenkins: j}
+	_, err := j.Requester.GetJSONContext(ctx, j.GetQueueUrl()+"/item/"+strconv.FormatInt(id, 10), item, nil)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return response.StatusCode, nil
+	return item, nil
+}
+
+// Wait until the queue item starts building and return the build.
+func (j *Jenkins) WaitForQueueItem(id int64, opts *WaitOptions) (*Build, error) {
+	return j.WaitForQueueItemContext(context.Background(), id, opts)
+}
+
+func (j *Jenkins) WaitForQueueItemContext(ctx context.Context, id int64, opts *WaitOptions) (*Build, error) {
+	item, err := j.waitForQueueItem(ctx, id, opts)
+	if err != nil {
+		return nil, err
+	}
+	return item.GetBuildContext(ctx)
+}
+
+func (j *Jenkins) waitForQueueItem(ctx context.Context, id int64, opts *WaitOptions) (*QueueItem, error) {
+	ctx, cancel, interval := opts.context(ctx)
+	defer cancel()
+	for {
+		item, err := j.GetQueueItemContext(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("waiting for queue item %d: %w", id, err)
+		}
+		if item.Executable != nil {
+			return item, nil
+		}
+		if item.Cancelled {
+			return nil, fmt.Errorf("%w: %d", ErrQueueItemCancelled, id)
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, fmt.Errorf("waiting for queue item %d: %w", id, err)
+		}
+	}
+}
+
+// pathOf returns the path of an absolute Jenkins URL relative to the base
+// URL, e.g. "/job/app" for "https://ci.example.com/jenkins/job/app/".
+func (j *Jenkins) pathOf(rawURL string) string {
+	p := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		p = u.Path
+	}
+	if base, err := url.Parse(j.Server); err == nil {
+		p = strings.TrimPrefix(p, strings.TrimSuffix(base.Path, "/"))
+	}
+	return "/" + strings.Trim(p, "/")
 }
@@ -0,0 +1,188 @@
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTriggerAndWait(t *testing.T) {
+	var itemPolls, buildPolls int
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/job/app/api/json":
+			w.Write([]byte(`{"name":"app"}`))
+		case "/job/app/build":
+			w.Header().Set("Location", ts.URL+"/queue/item/7/")
+			w.WriteHeader(http.StatusCreated)
+		case "/queue/item/7/api/json":
+			itemPolls++
+			if itemPolls == 1 {
+				w.Write([]byte(`{"id":7,"why":"Waiting for next available executor"}`))
+				return
+			}
+			w.Write([]byte(`{"id":7,"task":{"name":"app","url":"` + ts.URL + `/job/app/"},"executable":{"number":12,"url":"` + ts.URL + `/job/app/12/"}}`))
+		case "/job/app/12/api/json":
+			buildPolls++
+			if buildPolls <= 2 {
+				w.Write([]byte(`{"number":12,"building":true}`))
+				return
+			}
+			w.Write([]byte(`{"number":12,"building":false,"result":"UNSTABLE"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	job := &Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/app"}
+	build, err := job.TriggerAndWait(nil, &WaitOptions{PollInterval: time.Millisecond, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal("TriggerAndWait failed:", err)
+	}
+	if build.GetBuildNumber() != 12 || build.GetResult() != "UNSTABLE" || itemPolls != 2 {
+		t.Fatal("unexpected build:", build.GetBuildNumber(), build.GetResult(), itemPolls)
+	}
+
+	build, err = j.WaitForQueueItem(7, &WaitOptions{PollInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal("WaitForQueueItem failed:", err)
+	}
+	if build.Job.GetName() != "app" || build.Base != "/job/app/12" {
+		t.Fatal("queue item resolved to the wrong build:", build.Base)
+	}
+}
+
+func TestWaitForQueueItem(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/queue/item/1/api/json":
+			w.Write([]byte(`{"id":1,"cancelled":true}`))
+		case "/queue/item/2/api/json":
+			w.Write([]byte(`{"id":2,"blocked":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	if _, err := j.WaitForQueueItem(1, nil); !errors.Is(err, ErrQueueItemCancelled) {
+		t.Fatal("expected cancelled queue item, got", err)
+	}
+	_, err := j.WaitForQueueItem(2, &WaitOptions{PollInterval: time.Millisecond, Timeout: 20 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected timeout, got", err)
+	}
+}
+
+func TestPathOf(t *testing.T) {
+	j := &Jenkins{Server: "https://ci.example.com/jenkins/"}
+	if p := j.pathOf("https://ci.example.com/jenkins/job/team/job/app/"); p != "/job/team/job/app" {
+		t.Fatal("unexpected path:", p)
+	}
+}
+
+func TestHasQueuedBuild(t *testing.T) {
+	queued := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !queued {
+			w.Write([]byte(`{"name":"app","inQueue":false,"queueItem":null}`))
+			return
+		}
+		w.Write([]byte(`{"name":"app","inQueue":true,"queueItem":{"id":42,"blocked":true,"buildable":false,"stuck":false,
+			"inQueueSince":1600000000000,"params":"\nVERSION=1.2","why":"Build #3 is already in progress",
+			"actions":[{"parameters":[{"name":"VERSION","value":"1.2"}]}]}}`))
+	}))
+	defer ts.Close()
+
+	job := &Job{Jenkins: CreateJenkins(nil, ts.URL), Raw: new(JobResponse), Base: "/job/app"}
+	item, ok, err := job.HasQueuedBuild()
+	if err != nil || !ok {
+		t.Fatal("expected a queued build:", err)
+	}
+	if item.ID != 42 || !item.Blocked || item.Why != "Build #3 is already in progress" || item.Jenkins != job.Jenkins {
+		t.Fatal("unexpected queue item:", item)
+	}
+	if item.QueuedAt().Unix() != 1600000000 || item.GetParameters()[0].Value != "1.2" {
+		t.Fatal("unexpected queue item details:", item.QueuedAt(), item.GetParameters())
+	}
+	if _, err := item.GetBuild(); err == nil {
+		t.Fatal("expected error for an item that has not started")
+	}
+
+	queued = false
+	if item, ok, err = job.HasQueuedBuild(); err != nil || ok || item != nil {
+		t.Fatal("expected no queued build:", item, ok, err)
+	}
+}
+
+func TestQueueTasks(t *testing.T) {
+	var cancelled []string
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/queue/api/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"items":[{"id":1,"task":{"name":"app"}},{"id":2,"task":{"name":"lib"}},{"id":3,"task":{"name":"app"}}]}`))
+		case "/queue/cancelItem":
+			id := r.URL.Query().Get("id")
+			if id == "3" {
+				http.Error(w, "not allowed", http.StatusForbidden)
+				return
+			}
+			mu.Lock()
+			cancelled = append(cancelled, id)
+			mu.Unlock()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	q, err := CreateJenkins(nil, ts.URL).GetQueue()
+	if err != nil {
+		t.Fatal("GetQueue failed:", err)
+	}
+	tasks := q.Tasks()
+	if len(tasks) != 3 || tasks[0].Raw.ID != 1 || tasks[1].Raw.ID != 2 || tasks[2].Raw.ID != 3 {
+		t.Fatal("tasks share their item")
+	}
+	if jobTasks := q.GetTasksForJob("app"); len(jobTasks) != 2 || jobTasks[0].Raw == jobTasks[1].Raw {
+		t.Fatal("unexpected tasks for job:", jobTasks)
+	}
+
+	if _, err := q.CancelTask(99); err == nil {
+		t.Fatal("expected error for unknown task")
+	}
+
+	// Reading tasks while the queue refreshes must be safe.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.Poll()
+			q.GetTaskById(2)
+		}()
+	}
+	wg.Wait()
+
+	results := q.CancelTasksForJob("app")
+	if len(results) != 2 || results[0].Err != nil || !IsForbidden(results[1].Err) || results[1].Task.Raw.ID != 3 {
+		t.Fatal("unexpected cancel results:", results)
+	}
+	if strings.Join(cancelled, ",") != "1" {
+		t.Fatal("unexpected cancelled items:", cancelled)
+	}
+}
@@ -0,0 +1,190 @@
+package gojenkins
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+type QueueEventType int
+
+const (
+	// The item entered the queue, or was there when watching started.
+	QueueItemAdded QueueEventType = iota
+	// The item is ready to run and waits for an executor.
+	QueueItemBuildable
+	// The item can't run yet, e.g. because a build of the job is in
+	// progress. Reason says why.
+	QueueItemBlocked
+	// The item has been buildable for long without getting an executor.
+	// Reason says why.
+	QueueItemStuck
+	// The item has waited for an executor longer than
+	// QueueWatchOptions.MaxWait.
+	QueueItemWaitingTooLong
+	// The item left the queue to start building. Item.Executable is set
+	// unless Jenkins already forgot the item.
+	QueueItemLeft
+	// The item left the queue because it was cancelled.
+	QueueItemCancelled
+	// Polling the queue failed. The watcher keeps polling.
+	QueueWatchFailed
+)
+
+func (t QueueEventType) String() string {
+	switch t {
+	case QueueItemAdded:
+		return "added"
+	case QueueItemBuildable:
+		return "buildable"
+	case QueueItemBlocked:
+		return "blocked"
+	case QueueItemStuck:
+		return "stuck"
+	case QueueItemWaitingTooLong:
+		return "waiting too long"
+	case QueueItemLeft:
+		return "left"
+	case QueueItemCancelled:
+		return "cancelled"
+	case QueueWatchFailed:
+		return "watch failed"
+	}
+	return "unknown"
+}
+
+// QueueEvent is a change of the build queue seen by WatchQueue.
+type QueueEvent struct {
+	Type QueueEventType
+	Item *QueueItem
+	// Reason is the explanation Jenkins gives for a waiting item.
+	Reason string
+	// Err is set for QueueWatchFailed events.
+	Err error
+}
+
+// QueueWatchOptions controls WatchQueue.
+type QueueWatchOptions struct {
+	// PollInterval is the delay between two snapshots of the queue.
+	PollInterval time.Duration
+	// MaxWait, if set, reports items that have been buildable or stuck that
+	// long, i.e. waited for an executor, once per item. Time spent blocked
+	// or in the quiet period doesn't count.
+	MaxWait time.Duration
+}
+
+// Poll the queue until ctx is done and send an event for every change
+// between two snapshots. The channel is closed when ctx is done.
+func (j *Jenkins) WatchQueue(ctx context.Context, opts *QueueWatchOptions) <-chan QueueEvent {
+	var o QueueWatchOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultWaitPollInterval
+	}
+
+	events := make(chan QueueEvent)
+	go func() {
+		defer close(events)
+		w := &queueWatcher{jenkins: j, maxWait: o.MaxWait, overdue: map[int64]bool{}}
+		for {
+			for _, event := range w.poll(ctx) {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err := sleepContext(ctx, o.PollInterval); err != nil {
+				return
+			}
+		}
+	}()
+	return events
+}
+
+type queueWatcher struct {
+	jenkins *Jenkins
+	maxWait time.Duration
+	// items is the last snapshot, nil before the first one.
+	items map[int64]QueueItem
+	// overdue holds the items already reported as waiting too long.
+	overdue map[int64]bool
+}
+
+func (w *queueWatcher) poll(ctx context.Context) []QueueEvent {
+	q := &Queue{Jenkins: w.jenkins, Raw: new(queueResponse), Base: w.jenkins.GetQueueUrl()}
+	if _, err := q.PollContext(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return []QueueEvent{{Type: QueueWatchFailed, Err: err}}
+	}
+	var items []QueueItem
+	for _, t := range q.Tasks() {
+		items = append(items, *t.Raw)
+	}
+
+	events := w.diff(items, time.Now())
+	// Ask Jenkins what became of the items that left.
+	for i, event := range events {
+		if event.Type != QueueItemLeft {
+			continue
+		}
+		item, err := w.jenkins.GetQueueItemContext(ctx, event.Item.ID)
+		if err != nil {
+			continue
+		}
+		events[i].Item = item
+		if item.Cancelled {
+			events[i].Type = QueueItemCancelled
+		}
+	}
+	return events
+}
+
+// diff records items as the current snapshot and returns the events
+// leading to it from the previous one.
+func (w *queueWatcher) diff(items []QueueItem, now time.Time) []QueueEvent {
+	var events []QueueEvent
+	current := make(map[int64]QueueItem, len(items))
+	for i := range items {
+		item := items[i]
+		current[item.ID] = item
+
+		prev, known := w.items[item.ID]
+		if !known {
+			events = append(events, QueueEvent{Type: QueueItemAdded, Item: &item})
+		}
+		if item.Buildable && !prev.Buildable {
+			events = append(events, QueueEvent{Type: QueueItemBuildable, Item: &item})
+		}
+		if item.Blocked && !prev.Blocked {
+			events = append(events, QueueEvent{Type: QueueItemBlocked, Item: &item, Reason: item.Why})
+		}
+		if item.Stuck && !prev.Stuck {
+			events = append(events, QueueEvent{Type: QueueItemStuck, Item: &item, Reason: item.Why})
+		}
+		if since := item.BuildableSince(); w.maxWait > 0 && !since.IsZero() && !w.overdue[item.ID] && now.Sub(since) > w.maxWait {
+			w.overdue[item.ID] = true
+			events = append(events, QueueEvent{Type: QueueItemWaitingTooLong, Item: &item, Reason: item.Why})
+		}
+	}
+
+	var left []int64
+	for id := range w.items {
+		if _, ok := current[id]; !ok {
+			left = append(left, id)
+		}
+	}
+	sort.Slice(left, func(a, b int) bool { return left[a] < left[b] })
+	for _, id := range left {
+		item := w.items[id]
+		delete(w.overdue, id)
+		events = append(events, QueueEvent{Type: QueueItemLeft, Item: &item})
+	}
+
+	w.items = current
+	return events
+}
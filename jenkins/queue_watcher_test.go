@@ -0,0 +1,101 @@
+package gojenkins
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueueWatcherDiff(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	queued := now.Add(-time.Minute).UnixNano() / int64(time.Millisecond)
+	w := &queueWatcher{maxWait: 10 * time.Minute, overdue: map[int64]bool{}}
+
+	events := w.diff([]QueueItem{{ID: 1, InQueueSince: queued}}, now)
+	if len(events) != 1 || events[0].Type != QueueItemAdded {
+		t.Fatal("unexpected events for first snapshot:", events)
+	}
+
+	events = w.diff([]QueueItem{
+		{ID: 1, InQueueSince: queued, Buildable: true, Stuck: true, Why: "Waiting for next available executor"},
+		{ID: 2, InQueueSince: queued, Blocked: true, Why: "Build #3 is already in progress"},
+	}, now)
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Type.String()+":"+e.Reason)
+	}
+	want := "buildable:,stuck:Waiting for next available executor,added:,blocked:Build #3 is already in progress"
+	if strings.Join(got, ",") != want {
+		t.Fatal("unexpected events:", got)
+	}
+
+	// Waiting while blocked doesn't count towards MaxWait.
+	later := now.Add(15 * time.Minute)
+	events = w.diff([]QueueItem{{ID: 2, InQueueSince: queued, Blocked: true, Why: "Build #3 is already in progress"}}, later)
+	if len(events) != 1 || events[0].Type != QueueItemLeft || events[0].Item.ID != 1 {
+		t.Fatal("unexpected events:", events)
+	}
+
+	// Item 2 then waits for an executor past MaxWait and is reported once.
+	buildable := later.UnixNano() / int64(time.Millisecond)
+	for i := 0; i < 3; i++ {
+		at := later.Add(time.Duration(i) * 11 * time.Minute)
+		events = w.diff([]QueueItem{{ID: 2, InQueueSince: queued, Buildable: true, BuildableStartMilliseconds: buildable, Why: "Waiting for next available executor"}}, at)
+		if i == 0 && (len(events) != 1 || events[0].Type != QueueItemBuildable) {
+			t.Fatal("unexpected events:", events)
+		}
+		if i == 1 && (len(events) != 1 || events[0].Type != QueueItemWaitingTooLong) {
+			t.Fatal("buildable item not reported as waiting too long:", events)
+		}
+		if i == 2 && len(events) != 0 {
+			t.Fatal("overdue item reported again:", events)
+		}
+	}
+}
+
+func TestWatchQueue(t *testing.T) {
+	var mu sync.Mutex
+	snapshots := []string{
+		`{"items":[{"id":5,"task":{"name":"app"}},{"id":6,"task":{"name":"lib"}}]}`,
+		`{"items":[]}`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/queue/api/json":
+			mu.Lock()
+			w.Write([]byte(snapshots[0]))
+			if len(snapshots) > 1 {
+				snapshots = snapshots[1:]
+			}
+			mu.Unlock()
+		case "/queue/item/5/api/json":
+			w.Write([]byte(`{"id":5,"executable":{"number":9}}`))
+		case "/queue/item/6/api/json":
+			w.Write([]byte(`{"id":6,"cancelled":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []QueueEvent
+	for event := range CreateJenkins(nil, ts.URL).WatchQueue(ctx, &QueueWatchOptions{PollInterval: time.Millisecond}) {
+		got = append(got, event)
+		if len(got) == 4 {
+			cancel()
+		}
+	}
+	if len(got) != 4 || got[0].Type != QueueItemAdded || got[0].Item.Task.Name != "app" {
+		t.Fatal("unexpected events:", got)
+	}
+	if got[2].Type != QueueItemLeft || got[2].Item.Executable.Number != 9 || got[3].Type != QueueItemCancelled {
+		t.Fatal("unexpected events for items leaving the queue:", got[2], got[3])
+	}
+}
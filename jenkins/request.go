@@ -2,6 +2,7 @@ package gojenkins
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // Request Methods
@@ -40,79 +42,130 @@ func NewAPIRequest(method string, endpoint string, payload io.Reader) *APIReques
 type Requester struct {
 	Base      string
 	BasicAuth *BasicAuth
-	Client    *http.Client
-	CACert    []byte
+	// Auth takes precedence over BasicAuth when set.
+	Auth   Authenticator
+	Client *http.Client
+	// CACert is a PEM bundle trusted in addition to the system roots.
+	CACert []byte
+	// SslVerify enables verification of the server certificate. CreateJenkins
+	// sets it; a Requester built as a literal must set it too, or certificates
+	// are not verified. A warning is logged when verification is off.
 	SslVerify bool
+	// ClientCert and ClientKey are a PEM key pair presented for mutual TLS.
+	ClientCert []byte
+	ClientKey  []byte
+	// ServerName overrides the host name used to verify the server
+	// certificate.
+	ServerName string
+	// Retry controls how failed requests are retried. A nil policy
+	// disables retries.
+	Retry *RetryPolicy
+	// Logger defaults to discarding messages.
+	Logger Logger
+
+	middlewares []Middleware
+
+	crumbMu sync.Mutex
+	crumb   *crumb
+	jarOnce sync.Once
+	jar     http.CookieJar
 }
 
+// Every request helper has a Context variant (GetJSONContext, PostContext,
+// DoContext, ...). The context is attached to the outgoing *http.Request, so
+// cancelling it or hitting its deadline aborts the call in flight. The plain
+// variants use context.Background().
+
 func (r *Requester) SetCrumb(ar *APIRequest) error {
-	crumbData := map[string]string{}
-	response, _ := r.GetJSON("/crumbIssuer/api/json", &crumbData, nil)
+	return r.SetCrumbContext(context.Background(), ar)
+}
 
-	if response.StatusCode == 200 && crumbData["crumbRequestField"] != "" {
-		ar.SetHeader(crumbData["crumbRequestField"], crumbData["crumb"])
+// SetCrumbContext adds the CSRF crumb header to ar. The crumb is fetched
+// once and reused until Jenkins rejects it.
+func (r *Requester) SetCrumbContext(ctx context.Context, ar *APIRequest) error {
+	c, err := r.getCrumb(ctx)
+	if err != nil {
+		return err
+	}
+	if c.RequestField != "" {
+		ar.SetHeader(c.RequestField, c.Crumb)
 	}
-
 	return nil
 }
 
 func (r *Requester) PostJSON(endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.PostJSONContext(context.Background(), endpoint, payload, responseStruct, querystring)
+}
+
+func (r *Requester) PostJSONContext(ctx context.Context, endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("POST", endpoint, payload)
-	if err := r.SetCrumb(ar); err != nil {
-		return nil, err
-	}
 	ar.SetHeader("Content-Type", "application/x-www-form-urlencoded")
 	ar.Suffix = "api/json"
-	return r.Do(ar, &responseStruct, querystring)
+	return r.doWithCrumb(ctx, ar, &responseStruct, querystring)
 }
 
 func (r *Requester) Post(endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.PostContext(context.Background(), endpoint, payload, responseStruct, querystring)
+}
+
+func (r *Requester) PostContext(ctx context.Context, endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("POST", endpoint, payload)
-	if err := r.SetCrumb(ar); err != nil {
-		return nil, err
-	}
 	ar.SetHeader("Content-Type", "application/x-www-form-urlencoded")
 	ar.Suffix = ""
-	return r.Do(ar, &responseStruct, querystring)
+	return r.doWithCrumb(ctx, ar, &responseStruct, querystring)
 }
 
 func (r *Requester) PostFiles(endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string, files []string) (*http.Response, error) {
+	return r.PostFilesContext(context.Background(), endpoint, payload, responseStruct, querystring, files)
+}
+
+func (r *Requester) PostFilesContext(ctx context.Context, endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string, files []string) (*http.Response, error) {
 	ar := NewAPIRequest("POST", endpoint, payload)
-	if err := r.SetCrumb(ar); err != nil {
-		return nil, err
-	}
-	return r.Do(ar, &responseStruct, querystring, files)
+	return r.doWithCrumb(ctx, ar, &responseStruct, querystring, files)
 }
 
 func (r *Requester) PostXML(endpoint string, xml string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.PostXMLContext(context.Background(), endpoint, xml, responseStruct, querystring)
+}
+
+func (r *Requester) PostXMLContext(ctx context.Context, endpoint string, xml string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	payload := bytes.NewBuffer([]byte(xml))
 	ar := NewAPIRequest("POST", endpoint, payload)
-	if err := r.SetCrumb(ar); err != nil {
-		return nil, err
-	}
 	ar.SetHeader("Content-Type", "application/xml")
 	ar.Suffix = ""
-	return r.Do(ar, &responseStruct, querystring)
+	return r.doWithCrumb(ctx, ar, &responseStruct, querystring)
 }
 
 func (r *Requester) GetJSON(endpoint string, responseStruct interface{}, query map[string]string) (*http.Response, error) {
+	return r.GetJSONContext(context.Background(), endpoint, responseStruct, query)
+}
+
+func (r *Requester) GetJSONContext(ctx context.Context, endpoint string, responseStruct interface{}, query map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("GET", endpoint, nil)
 	ar.SetHeader("Content-Type", "application/json")
 	ar.Suffix = "api/json"
-	return r.Do(ar, &responseStruct, query)
+	return r.DoContext(ctx, ar, &responseStruct, query)
 }
 
 func (r *Requester) GetXML(endpoint string, responseStruct interface{}, query map[string]string) (*http.Response, error) {
+	return r.GetXMLContext(context.Background(), endpoint, responseStruct, query)
+}
+
+func (r *Requester) GetXMLContext(ctx context.Context, endpoint string, responseStruct interface{}, query map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("GET", endpoint, nil)
 	ar.SetHeader("Content-Type", "application/xml")
 	ar.Suffix = ""
-	return r.Do(ar, responseStruct, query)
+	return r.DoContext(ctx, ar, responseStruct, query)
 }
 
 func (r *Requester) Get(endpoint string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.GetContext(context.Background(), endpoint, responseStruct, querystring)
+}
+
+func (r *Requester) GetContext(ctx context.Context, endpoint string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("GET", endpoint, nil)
 	ar.Suffix = ""
-	return r.Do(ar, responseStruct, querystring)
+	return r.DoContext(ctx, ar, responseStruct, querystring)
 }
 
 func (r *Requester) SetClient(client *http.Client) *Requester {
@@ -120,15 +173,33 @@ func (r *Requester) SetClient(client *http.Client) *Requester {
 	return r
 }
 
-//Add auth on redirect if required.
+//Add auth on redirect if required. Credentials are only sent to the scheme,
+//host and port of the original request, so never over http after https.
+//net/http keeps Authorization on redirects to other ports of the same host
+//and keeps every other header, so both are dropped here.
 func (r *Requester) redirectPolicyFunc(req *http.Request, via []*http.Request) error {
-	if r.BasicAuth != nil {
-		req.SetBasicAuth(r.BasicAuth.Username, r.BasicAuth.Password)
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
 	}
-	return nil
+	auth := r.authenticator()
+	if auth == nil {
+		return nil
+	}
+	if req.URL.Host != via[0].URL.Host || req.URL.Scheme != via[0].URL.Scheme {
+		req.Header.Del("Authorization")
+		if proxy, ok := auth.(*ProxyHeaderAuth); ok {
+			req.Header.Del(proxy.Header)
+		}
+		return nil
+	}
+	return auth.Authenticate(req)
 }
 
 func (r *Requester) Do(ar *APIRequest, responseStruct interface{}, options ...interface{}) (*http.Response, error) {
+	return r.DoContext(context.Background(), ar, responseStruct, options...)
+}
+
+func (r *Requester) DoContext(ctx context.Context, ar *APIRequest, responseStruct interface{}, options ...interface{}) (*http.Response, error) {
 	if !strings.HasSuffix(ar.Endpoint, "/") && ar.Method != "POST" {
 		ar.Endpoint += "/"
 	}
@@ -156,7 +227,8 @@ func (r *Requester) Do(ar *APIRequest, responseStruct interface{}, options ...in
 			files = v
 		}
 	}
-	var req *http.Request
+	payload := ar.Payload
+	contentType := ""
 
 	if fileUpload {
 		body := &bytes.Buffer{}
@@ -164,13 +236,13 @@ func (r *Requester) Do(ar *APIRequest, responseStruct interface{}, options ...in
 		for _, file := range files {
 			fileData, err := os.Open(file)
 			if err != nil {
-				Error.Println(err.Error())
+				r.logger().Error("cannot open upload", "file", file, "error", err)
 				return nil, err
 			}
 
 			part, err := writer.CreateFormFile("file", filepath.Base(file))
 			if err != nil {
-				Error.Println(err.Error())
+				r.logger().Error("cannot add upload to form", "file", file, "error", err)
 				return nil, err
 			}
 			if _, err = io.Copy(part, fileData); err != nil {
@@ -179,7 +251,9 @@ func (r *Requester) Do(ar *APIRequest, responseStruct interface{}, options ...in
 			defer fileData.Close()
 		}
 		var params map[string]string
-		json.NewDecoder(ar.Payload).Decode(&params)
+		if ar.Payload != nil {
+			json.NewDecoder(ar.Payload).Decode(&params)
+		}
 		for key, val := range params {
 			if err = writer.WriteField(key, val); err != nil {
 				return nil, err
@@ -188,43 +262,95 @@ func (r *Requester) Do(ar *APIRequest, responseStruct interface{}, options ...in
 		if err = writer.Close(); err != nil {
 			return nil, err
 		}
-		req, err = http.NewRequest(ar.Method, URL.String(), body)
-		if err != nil {
+		payload = body
+		contentType = writer.FormDataContentType()
+	}
+
+	policy := r.retryPolicyFor(ctx, ar.Method)
+	var payloadData []byte
+	if policy != nil && payload != nil {
+		// Keep the payload so it can be sent again on retry.
+		if payloadData, err = ioutil.ReadAll(payload); err != nil {
 			return nil, err
 		}
-		req.Header.Set("Content-Type", writer.FormDataContentType())
-	} else {
+	}
 
-		req, err = http.NewRequest(ar.Method, URL.String(), ar.Payload)
+	for attempt := 1; ; attempt++ {
+		if payloadData != nil {
+			payload = bytes.NewReader(payloadData)
+		}
+		req, err := http.NewRequestWithContext(ctx, ar.Method, URL.String(), payload)
 		if err != nil {
 			return nil, err
 		}
-	}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
 
-	if r.BasicAuth != nil {
-		req.SetBasicAuth(r.BasicAuth.Username, r.BasicAuth.Password)
-	}
+		if auth := r.authenticator(); auth != nil {
+			if err := auth.Authenticate(req); err != nil {
+				return nil, err
+			}
+		}
 
-	for k := range ar.Headers {
-		req.Header.Add(k, ar.Headers.Get(k))
-	}
+		for k := range ar.Headers {
+			req.Header.Add(k, ar.Headers.Get(k))
+		}
 
-	if response, err := r.Client.Do(req); err != nil {
-		return nil, err
-	} else {
-		errorText := response.Header.Get("X-Error")
-		if errorText != "" {
-			return nil, errors.New(errorText)
+		// Jenkins binds crumbs to the web session, so keep its cookie
+		// unless the client manages cookies itself.
+		if r.Client.Jar == nil {
+			for _, cookie := range r.cookieJar().Cookies(req.URL) {
+				req.AddCookie(cookie)
+			}
+		}
+
+		response, err := r.send(ar, req)
+		if err == nil && r.Client.Jar == nil {
+			r.cookieJar().SetCookies(req.URL, response.Cookies())
+		}
+		if policy != nil && attempt < policy.MaxAttempts && policy.shouldRetry(ctx, response, err) {
+			delay := policy.backoff(attempt, response)
+			if response != nil {
+				io.Copy(ioutil.Discard, response.Body)
+				response.Body.Close()
+			}
+			if err := sleepContext(ctx, delay); err != nil {
+				return nil, err
+			}
+			continue
 		}
-		switch responseStruct.(type) {
-		case *string:
-			return r.ReadRawResponse(response, responseStruct)
-		default:
-			return r.ReadJSONResponse(response, responseStruct)
+		if err != nil {
+			return nil, err
 		}
+		return r.readResponse(ar, response, responseStruct)
+	}
+}
 
+func (r *Requester) readResponse(ar *APIRequest, response *http.Response, responseStruct interface{}) (*http.Response, error) {
+	// Failed responses are returned together with an *APIError so callers
+	// can still inspect headers.
+	if response.StatusCode >= 400 || response.Header.Get("X-Error") != "" {
+		defer response.Body.Close()
+		return response, newAPIError(response)
 	}
 
+	switch v := responseStruct.(type) {
+	case *string:
+		return r.ReadRawResponse(response, responseStruct)
+	case *io.ReadCloser:
+		// The caller streams the body and must close it.
+		*v = response.Body
+		return response, nil
+	default:
+		if ar.Method == "POST" && !isJSONResponse(response) {
+			// Jenkins answers form POSTs with HTML pages.
+			defer response.Body.Close()
+			io.Copy(ioutil.Discard, response.Body)
+			return response, nil
+		}
+		return r.ReadJSONResponse(response, responseStruct)
+	}
 }
 
 func (r *Requester) ReadRawResponse(response *http.Response, responseStruct interface{}) (*http.Response, error) {
@@ -243,9 +369,49 @@ func (r *Requester) ReadRawResponse(response *http.Response, responseStruct inte
 	return response, nil
 }
 
+// ReadJSONResponse decodes the body of response into responseStruct.
+// Responses that are neither declared as JSON nor served from an api/json
+// endpoint fail when there is a responseStruct to decode into, e.g. for the
+// HTML login page of an SSO proxy; otherwise they are discarded.
 func (r *Requester) ReadJSONResponse(response *http.Response, responseStruct interface{}) (*http.Response, error) {
 	defer response.Body.Close()
 
-	json.NewDecoder(response.Body).Decode(responseStruct)
+	if !isJSONResponse(response) {
+		content, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return response, err
+		}
+		if len(bytes.TrimSpace(content)) > 0 && wantsDecoding(responseStruct) {
+			return response, fmt.Errorf("expected JSON from %s, got %q", requestURL(response), response.Header.Get("Content-Type"))
+		}
+		return response, nil
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(responseStruct); err != nil && err != io.EOF {
+		return response, fmt.Errorf("decoding response from %s: %w", requestURL(response), err)
+	}
 	return response, nil
 }
+
+func isJSONResponse(response *http.Response) bool {
+	if strings.Contains(response.Header.Get("Content-Type"), "json") {
+		return true
+	}
+	return response.Request != nil && strings.HasSuffix(response.Request.URL.Path, "/api/json")
+}
+
+// wantsDecoding reports whether v is somewhere to decode a response into.
+// GetJSON passes a pointer to its interface{} argument.
+func wantsDecoding(v interface{}) bool {
+	if p, ok := v.(*interface{}); ok {
+		return p != nil && *p != nil
+	}
+	return v != nil
+}
+
+func requestURL(response *http.Response) string {
+	if response.Request == nil {
+		return "response"
+	}
+	return response.Request.URL.String()
+}
@@ -0,0 +1,112 @@
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetJSONContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	j := CreateJenkins(nil, ts.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := j.Requester.GetJSONContext(ctx, "/", new(ExecutorResponse), nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected deadline exceeded, got:", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("request was not aborted by the context deadline")
+	}
+}
+
+func TestJobPollContextCancelled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not reach the server:", r.URL.Path)
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := j.GetJobContext(ctx, "job"); !errors.Is(err, context.Canceled) {
+		t.Fatal("expected context canceled, got:", err)
+	}
+}
+
+func TestNonJSONResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/crumbIssuer/") {
+			http.NotFound(w, r)
+			return
+		}
+		// An SSO proxy answering with its login page.
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><form action=/login></form></html>"))
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	b := &Build{Jenkins: j, Base: "/job/app/1", Raw: new(BuildResponse)}
+	if _, err := b.GetPipelineRun(); err == nil {
+		t.Fatal("HTML page decoded as a pipeline run")
+	}
+	// Form posts are answered with HTML pages.
+	if _, err := j.Requester.PostContext(context.Background(), "/job/app/build", nil, new(JobResponse), nil); err != nil {
+		t.Fatal("POST failed:", err)
+	}
+	if _, err := j.Requester.GetContext(context.Background(), "/job/app/1/stop", nil, nil); err != nil {
+		t.Fatal("GET without response struct failed:", err)
+	}
+
+	// A response without a request must not panic.
+	response := &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"application/json"}}, Body: ioutil.NopCloser(strings.NewReader("{"))}
+	if _, err := j.Requester.ReadJSONResponse(response, new(JobResponse)); err == nil {
+		t.Fatal("invalid JSON decoded")
+	}
+}
+
+func TestJenkinsInfo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html;charset=utf-8")
+			w.Write([]byte("<html>Dashboard</html>"))
+		case "/api/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"nodeName":"","numExecutors":2}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	j, err := CreateJenkins(nil, ts.URL).Init()
+	if err != nil {
+		t.Fatal("Init failed:", err)
+	}
+	info, err := j.Info()
+	if err != nil {
+		t.Fatal("Info failed:", err)
+	}
+	if info.NumExecutors != 2 {
+		t.Fatal("unexpected info:", info)
+	}
+}
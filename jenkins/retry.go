@@ -0,0 +1,145 @@
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy describes how Requester retries requests that failed because
+// Jenkins was briefly unavailable.
+//
+// Only idempotent requests (GET, HEAD, OPTIONS) are retried unless the
+// context was marked with WithRetry.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. It doubles with
+	// every further attempt, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Jitter randomizes each delay by up to this fraction in both
+	// directions, e.g. 0.2 for +/-20%.
+	Jitter float64
+	// RetryableStatusCodes lists the response codes worth another attempt.
+	// Transport errors are retried if they are likely temporary, such as a
+	// refused or reset connection or a timeout, but not certificate errors.
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryPolicy returns the policy installed by CreateJenkins.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+type retryKey struct{}
+
+// WithRetry returns a copy of ctx that allows the Requester to retry
+// non-idempotent requests, such as Job.InvokeSimpleContext, made with it.
+// Only use it when repeating the call is harmless.
+func WithRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+func retryAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(retryKey{}).(bool)
+	return allowed
+}
+
+// retryPolicyFor returns the policy that applies to a request with the given
+// method, or nil if it must not be retried.
+func (r *Requester) retryPolicyFor(ctx context.Context, method string) *RetryPolicy {
+	if r.Retry == nil || r.Retry.MaxAttempts <= 1 {
+		return nil
+	}
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return r.Retry
+	}
+	if retryAllowed(ctx) {
+		return r.Retry
+	}
+	return nil
+}
+
+func (p *RetryPolicy) shouldRetry(ctx context.Context, response *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return isTemporary(err)
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if response.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isTemporary reports whether a transport error may go away on its own.
+func isTemporary(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// backoff returns the delay before the next attempt. A Retry-After header
+// sent by Jenkins takes precedence as long as it does not exceed MaxBackoff.
+func (p *RetryPolicy) backoff(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if secs, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			if d := time.Duration(secs) * time.Second; p.MaxBackoff <= 0 || d <= p.MaxBackoff {
+				return d
+			}
+		}
+	}
+
+	d := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	return d
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
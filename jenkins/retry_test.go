@@ -0,0 +1,138 @@
+package gojenkins
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRetryPolicy() *RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.InitialBackoff = time.Millisecond
+	p.MaxBackoff = 5 * time.Millisecond
+	return p
+}
+
+func TestGetRetriedOnUnavailable(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "job"}`))
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	j.Requester.Retry = newTestRetryPolicy()
+	job, err := j.GetJob("job")
+	if err != nil {
+		t.Fatal("GetJob failed:", err)
+	}
+	if attempts != 3 || job.GetName() != "job" {
+		t.Fatal("unexpected result after", attempts, "attempts:", job.GetName())
+	}
+}
+
+func TestGetRetryGivesUp(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	j.Requester.Retry = newTestRetryPolicy()
+	_, err := j.GetJob("job")
+	if StatusCode(err) != http.StatusBadGateway {
+		t.Fatal("expected bad gateway error, got:", err)
+	}
+	if attempts != j.Requester.Retry.MaxAttempts {
+		t.Fatal("expected", j.Requester.Retry.MaxAttempts, "attempts, got", attempts)
+	}
+}
+
+func TestRetryOnlyTemporaryErrors(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// Drop the connection without a response.
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "job"}`))
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	j.Requester.Retry = newTestRetryPolicy()
+	if _, err := j.GetJob("job"); err != nil || attempts != 2 {
+		t.Fatal("expected a retry after the dropped connection, got", attempts, "attempts:", err)
+	}
+
+	// A certificate the client doesn't trust won't become trusted.
+	var connections int32
+	tlsServer := httptest.NewUnstartedServer(http.NotFoundHandler())
+	tlsServer.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	j = CreateJenkins(nil, tlsServer.URL)
+	j.Requester.Retry = newTestRetryPolicy()
+	if _, err := j.GetJob("job"); err == nil {
+		t.Fatal("expected certificate error")
+	}
+	if n := atomic.LoadInt32(&connections); n != 1 {
+		t.Fatal("expected a single attempt, got", n)
+	}
+}
+
+func TestPostRetryRequiresOptIn(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			http.NotFound(w, r)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	j.Requester.Retry = newTestRetryPolicy()
+
+	_, err := j.Requester.Post("/job/x/build", strings.NewReader("a=b"), nil, nil)
+	if StatusCode(err) != http.StatusServiceUnavailable || len(bodies) != 1 {
+		t.Fatal("POST without opt-in should not be retried:", err, bodies)
+	}
+
+	bodies = nil
+	_, err = j.Requester.PostContext(WithRetry(context.Background()), "/job/x/build", strings.NewReader("a=b"), nil, nil)
+	if err != nil {
+		t.Fatal("POST with opt-in failed:", err)
+	}
+	if len(bodies) != 2 || bodies[0] != "a=b" || bodies[1] != "a=b" {
+		t.Fatal("payload was not replayed on retry:", bodies)
+	}
+}
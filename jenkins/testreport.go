@@ -0,0 +1,188 @@
+package gojenkins
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// TestResult is the test report of a build. Durations are in seconds.
+type TestResult struct {
+	Duration  float64     `json:"duration"`
+	Empty     bool        `json:"empty"`
+	FailCount int64       `json:"failCount"`
+	PassCount int64       `json:"passCount"`
+	SkipCount int64       `json:"skipCount"`
+	Suites    []TestSuite `json:"suites"`
+}
+
+type TestSuite struct {
+	Cases     []TestCase `json:"cases"`
+	Duration  float64    `json:"duration"`
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Stderr    string     `json:"stderr"`
+	Stdout    string     `json:"stdout"`
+	Timestamp string     `json:"timestamp"`
+}
+
+// TestCase is a single test. Status is one of STATUS_PASSED, STATUS_FIXED,
+// RESULT_STATUS_FAILED, STATUS_REGRESSION or RESULT_STATUS_SKIPPED.
+type TestCase struct {
+	Age             int64   `json:"age"`
+	ClassName       string  `json:"className"`
+	Duration        float64 `json:"duration"`
+	ErrorDetails    string  `json:"errorDetails"`
+	ErrorStackTrace string  `json:"errorStackTrace"`
+	FailedSince     int64   `json:"failedSince"`
+	Name            string  `json:"name"`
+	Skipped         bool    `json:"skipped"`
+	SkippedMessage  string  `json:"skippedMessage"`
+	Status          string  `json:"status"`
+	Stderr          string  `json:"stderr"`
+	Stdout          string  `json:"stdout"`
+}
+
+// FullName identifies the test across builds, e.g. "pkg.FooTest.testBar".
+func (c *TestCase) FullName() string {
+	return c.ClassName + "." + c.Name
+}
+
+func (c *TestCase) IsFailed() bool {
+	return c.Status == RESULT_STATUS_FAILED || c.Status == STATUS_REGRESSION
+}
+
+func (c *TestCase) IsPassed() bool {
+	return c.Status == STATUS_PASSED || c.Status == STATUS_FIXED
+}
+
+func (c *TestCase) IsSkipped() bool {
+	return c.Skipped || c.Status == RESULT_STATUS_SKIPPED
+}
+
+// Elapsed returns Duration as a time.Duration.
+func (c *TestCase) Elapsed() time.Duration {
+	return time.Duration(c.Duration * float64(time.Second))
+}
+
+// Returns all test cases of the report.
+func (r *TestResult) Cases() []TestCase {
+	var cases []TestCase
+	for _, suite := range r.Suites {
+		cases = append(cases, suite.Cases...)
+	}
+	return cases
+}
+
+// TestCaseChange pairs the results of a test in two builds. Base is nil
+// for a test that is new in Head.
+type TestCaseChange struct {
+	Name string
+	Base *TestCase
+	Head *TestCase
+}
+
+// DurationChange is how much longer the test took in Head.
+func (c TestCaseChange) DurationChange() time.Duration {
+	if c.Base == nil || c.Head == nil {
+		return 0
+	}
+	return c.Head.Elapsed() - c.Base.Elapsed()
+}
+
+// TestComparisonOptions controls what counts as a duration regression.
+type TestComparisonOptions struct {
+	// MinSlowdown is the least increase in duration reported, 1s if zero.
+	MinSlowdown time.Duration
+	// SlowdownRatio is the least relative increase reported, e.g. 0.5 for
+	// 50% slower, which is the default.
+	SlowdownRatio float64
+}
+
+// TestComparison lists how the tests of a head build differ from a base
+// build. Every list is sorted by test name, except Slower which starts
+// with the largest slowdown.
+type TestComparison struct {
+	NewlyFailing []TestCaseChange
+	NewlyPassing []TestCaseChange
+	StillFailing []TestCaseChange
+	NewlySkipped []TestCaseChange
+	// Slower lists tests passing in both builds that got slower.
+	Slower []TestCaseChange
+}
+
+// CompareTestResults compares the test report of head with that of base.
+func CompareTestResults(base *TestResult, head *TestResult, opts *TestComparisonOptions) *TestComparison {
+	o := TestComparisonOptions{MinSlowdown: time.Second, SlowdownRatio: 0.5}
+	if opts != nil {
+		if opts.MinSlowdown > 0 {
+			o.MinSlowdown = opts.MinSlowdown
+		}
+		if opts.SlowdownRatio > 0 {
+			o.SlowdownRatio = opts.SlowdownRatio
+		}
+	}
+
+	before := map[string]*TestCase{}
+	for _, c := range base.Cases() {
+		c := c
+		if _, ok := before[c.FullName()]; !ok {
+			before[c.FullName()] = &c
+		}
+	}
+
+	cmp := &TestComparison{}
+	seen := map[string]bool{}
+	for _, c := range head.Cases() {
+		c := c
+		name := c.FullName()
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		change := TestCaseChange{Name: name, Base: before[name], Head: &c}
+		prev := change.Base
+
+		switch {
+		case c.IsFailed() && prev != nil && prev.IsFailed():
+			cmp.StillFailing = append(cmp.StillFailing, change)
+		case c.IsFailed():
+			cmp.NewlyFailing = append(cmp.NewlyFailing, change)
+		case c.IsSkipped() && (prev == nil || !prev.IsSkipped()):
+			cmp.NewlySkipped = append(cmp.NewlySkipped, change)
+		case c.IsPassed() && prev != nil && prev.IsFailed():
+			cmp.NewlyPassing = append(cmp.NewlyPassing, change)
+		case c.IsPassed() && prev != nil && prev.IsPassed():
+			slowdown := change.DurationChange()
+			if slowdown >= o.MinSlowdown && float64(slowdown) >= o.SlowdownRatio*float64(prev.Elapsed()) {
+				cmp.Slower = append(cmp.Slower, change)
+			}
+		}
+	}
+
+	for _, list := range [][]TestCaseChange{cmp.NewlyFailing, cmp.NewlyPassing, cmp.StillFailing, cmp.NewlySkipped} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	}
+	sort.SliceStable(cmp.Slower, func(i, j int) bool {
+		return cmp.Slower[i].DurationChange() > cmp.Slower[j].DurationChange()
+	})
+	return cmp
+}
+
+// Compare the test report of this build with the one of base, e.g. the
+// last build of the target branch.
+func (b *Build) CompareTestResults(base *Build, opts *TestComparisonOptions) (*TestComparison, error) {
+	return b.CompareTestResultsContext(context.Background(), base, opts)
+}
+
+func (b *Build) CompareTestResultsContext(ctx context.Context, base *Build, opts *TestComparisonOptions) (*TestComparison, error) {
+	baseResult, err := base.GetResultSetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	headResult, err := b.GetResultSetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return CompareTestResults(baseResult, headResult, opts), nil
+}
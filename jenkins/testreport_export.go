@@ -0,0 +1,244 @@
+package gojenkins
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Test reports can be exported to keep them after Jenkins discards the
+// build: as JUnit XML, which most CI tools read, or as JSON in the schema
+// below, which keeps everything Jenkins reports and reads back into a
+// TestResult with ReadTestResultJSON.
+
+// TestReportJSONVersion is the version of the JSON schema written by
+// TestResult.WriteJSON. It changes only when fields are removed or their
+// meaning changes.
+const TestReportJSONVersion = 1
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	ID        string          `xml:"id,attr,omitempty"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+	Stdout    string          `xml:"system-out,omitempty"`
+	Stderr    string          `xml:"system-err,omitempty"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Skipped   *junitMessage `xml:"skipped"`
+	Stdout    string        `xml:"system-out,omitempty"`
+	Stderr    string        `xml:"system-err,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// Write the report as JUnit XML. Jenkins doesn't tell failures and errors
+// apart, so every failed test is written as a <failure>.
+func (r *TestResult) WriteJUnitXML(w io.Writer) error {
+	doc := junitTestSuites{Time: junitTime(r.Duration)}
+	for _, suite := range r.Suites {
+		s := junitTestSuite{
+			Name:      suite.Name,
+			ID:        suite.ID,
+			Timestamp: suite.Timestamp,
+			Tests:     len(suite.Cases),
+			Time:      junitTime(suite.Duration),
+			Stdout:    suite.Stdout,
+			Stderr:    suite.Stderr,
+		}
+		for _, c := range suite.Cases {
+			tc := junitTestCase{ClassName: c.ClassName, Name: c.Name, Time: junitTime(c.Duration), Stdout: c.Stdout, Stderr: c.Stderr}
+			switch {
+			case c.IsFailed():
+				s.Failures++
+				tc.Failure = &junitMessage{Message: c.ErrorDetails, Text: c.ErrorStackTrace}
+			case c.IsSkipped():
+				s.Skipped++
+				tc.Skipped = &junitMessage{Message: c.SkippedMessage}
+			}
+			s.Cases = append(s.Cases, tc)
+		}
+		doc.Tests += s.Tests
+		doc.Failures += s.Failures
+		doc.Skipped += s.Skipped
+		doc.Suites = append(doc.Suites, s)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitTime(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
+
+type testReportJSON struct {
+	Version   int             `json:"version"`
+	Duration  float64         `json:"duration"`
+	Empty     bool            `json:"empty"`
+	PassCount int64           `json:"passCount"`
+	FailCount int64           `json:"failCount"`
+	SkipCount int64           `json:"skipCount"`
+	Suites    []testSuiteJSON `json:"suites"`
+}
+
+type testSuiteJSON struct {
+	Name      string         `json:"name"`
+	ID        string         `json:"id,omitempty"`
+	Timestamp string         `json:"timestamp,omitempty"`
+	Duration  float64        `json:"duration"`
+	Stdout    string         `json:"stdout,omitempty"`
+	Stderr    string         `json:"stderr,omitempty"`
+	Cases     []testCaseJSON `json:"cases"`
+}
+
+type testCaseJSON struct {
+	ClassName string  `json:"className"`
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Duration  float64 `json:"duration"`
+	// Outcome is "passed", "failed" or "skipped", whatever Status is.
+	Outcome         string `json:"outcome"`
+	Age             int64  `json:"age,omitempty"`
+	FailedSince     int64  `json:"failedSince,omitempty"`
+	ErrorDetails    string `json:"errorDetails,omitempty"`
+	ErrorStackTrace string `json:"errorStackTrace,omitempty"`
+	SkippedMessage  string `json:"skippedMessage,omitempty"`
+	Stdout          string `json:"stdout,omitempty"`
+	Stderr          string `json:"stderr,omitempty"`
+}
+
+// Write the report as JSON. Durations are in seconds and every test case
+// has an "outcome" of "passed", "failed" or "skipped" next to the Jenkins
+// status; empty fields are left out.
+func (r *TestResult) WriteJSON(w io.Writer) error {
+	doc := testReportJSON{
+		Version:   TestReportJSONVersion,
+		Duration:  r.Duration,
+		Empty:     r.Empty,
+		PassCount: r.PassCount,
+		FailCount: r.FailCount,
+		SkipCount: r.SkipCount,
+		Suites:    []testSuiteJSON{},
+	}
+	for _, suite := range r.Suites {
+		s := testSuiteJSON{
+			Name:      suite.Name,
+			ID:        suite.ID,
+			Timestamp: suite.Timestamp,
+			Duration:  suite.Duration,
+			Stdout:    suite.Stdout,
+			Stderr:    suite.Stderr,
+			Cases:     []testCaseJSON{},
+		}
+		for _, c := range suite.Cases {
+			s.Cases = append(s.Cases, testCaseJSON{
+				ClassName:       c.ClassName,
+				Name:            c.Name,
+				Status:          c.Status,
+				Duration:        c.Duration,
+				Outcome:         testOutcome(&c),
+				Age:             c.Age,
+				FailedSince:     c.FailedSince,
+				ErrorDetails:    c.ErrorDetails,
+				ErrorStackTrace: c.ErrorStackTrace,
+				SkippedMessage:  c.SkippedMessage,
+				Stdout:          c.Stdout,
+				Stderr:          c.Stderr,
+			})
+		}
+		doc.Suites = append(doc.Suites, s)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func testOutcome(c *TestCase) string {
+	switch {
+	case c.IsFailed():
+		return "failed"
+	case c.IsSkipped():
+		return "skipped"
+	}
+	return "passed"
+}
+
+// Read a report written by TestResult.WriteJSON.
+func ReadTestResultJSON(r io.Reader) (*TestResult, error) {
+	var doc testReportJSON
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Version < 1 || doc.Version > TestReportJSONVersion {
+		return nil, fmt.Errorf("unsupported test report version %d", doc.Version)
+	}
+
+	result := &TestResult{
+		Duration:  doc.Duration,
+		Empty:     doc.Empty,
+		PassCount: doc.PassCount,
+		FailCount: doc.FailCount,
+		SkipCount: doc.SkipCount,
+	}
+	for _, suite := range doc.Suites {
+		s := TestSuite{
+			Name:      suite.Name,
+			ID:        suite.ID,
+			Timestamp: suite.Timestamp,
+			Duration:  suite.Duration,
+			Stdout:    suite.Stdout,
+			Stderr:    suite.Stderr,
+		}
+		for _, c := range suite.Cases {
+			s.Cases = append(s.Cases, TestCase{
+				ClassName:       c.ClassName,
+				Name:            c.Name,
+				Status:          c.Status,
+				Duration:        c.Duration,
+				Skipped:         c.Outcome == "skipped",
+				Age:             c.Age,
+				FailedSince:     c.FailedSince,
+				ErrorDetails:    c.ErrorDetails,
+				ErrorStackTrace: c.ErrorStackTrace,
+				SkippedMessage:  c.SkippedMessage,
+				Stdout:          c.Stdout,
+				Stderr:          c.Stderr,
+			})
+		}
+		result.Suites = append(result.Suites, s)
+	}
+	return result, nil
+}
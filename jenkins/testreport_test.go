@@ -0,0 +1,140 @@
+package gojenkins
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const baseTestReport = `{"duration":3.5,"failCount":2,"passCount":3,"skipCount":0,"suites":[{"name":"pkg.ApiTest","id":null,"timestamp":null,"cases":[
+	{"className":"pkg.ApiTest","name":"testGet","status":"PASSED","duration":0.2},
+	{"className":"pkg.ApiTest","name":"testPost","status":"FAILED","duration":0.1,"errorDetails":"expected 201","errorStackTrace":"at ApiTest.java:10"},
+	{"className":"pkg.ApiTest","name":"testPut","status":"REGRESSION","duration":0.1,"stdout":null},
+	{"className":"pkg.ApiTest","name":"testDelete","status":"PASSED","duration":1.0},
+	{"className":"pkg.ApiTest","name":"testHead","status":"PASSED","duration":0.5}]}]}`
+
+const headTestReport = `{"duration":6.25,"failCount":2,"passCount":2,"skipCount":1,"suites":[{"name":"pkg.ApiTest","cases":[
+	{"className":"pkg.ApiTest","name":"testGet","status":"REGRESSION","duration":0.2,"errorDetails":"timeout"},
+	{"className":"pkg.ApiTest","name":"testPost","status":"FIXED","duration":0.1},
+	{"className":"pkg.ApiTest","name":"testPut","status":"FAILED","duration":0.1,"age":2},
+	{"className":"pkg.ApiTest","name":"testDelete","status":"PASSED","duration":3.25},
+	{"className":"pkg.ApiTest","name":"testHead","status":"SKIPPED","skipped":true,"skippedMessage":"flaky"},
+	{"className":"pkg.ApiTest","name":"testPatch","status":"PASSED","duration":0.1}]}]}`
+
+func TestCompareTestResults(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/job/app/1/testReport/api/json":
+			w.Write([]byte(baseTestReport))
+		case "/job/app/2/testReport/api/json":
+			w.Write([]byte(headTestReport))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	base := &Build{Jenkins: j, Base: "/job/app/1", Raw: new(BuildResponse)}
+	head := &Build{Jenkins: j, Base: "/job/app/2", Raw: new(BuildResponse)}
+
+	result, err := base.GetResultSet()
+	if err != nil {
+		t.Fatal("GetResultSet failed:", err)
+	}
+	failed := result.Suites[0].Cases[1]
+	if !failed.IsFailed() || failed.ErrorDetails != "expected 201" || failed.ErrorStackTrace != "at ApiTest.java:10" {
+		t.Fatal("unexpected test case:", failed)
+	}
+
+	cmp, err := head.CompareTestResults(base, nil)
+	if err != nil {
+		t.Fatal("CompareTestResults failed:", err)
+	}
+	names := func(changes []TestCaseChange) (s []string) {
+		for _, c := range changes {
+			s = append(s, c.Head.Name)
+		}
+		return s
+	}
+	if n := names(cmp.NewlyFailing); len(n) != 1 || n[0] != "testGet" {
+		t.Fatal("unexpected newly failing tests:", n)
+	}
+	if n := names(cmp.NewlyPassing); len(n) != 1 || n[0] != "testPost" {
+		t.Fatal("unexpected newly passing tests:", n)
+	}
+	if n := names(cmp.StillFailing); len(n) != 1 || n[0] != "testPut" {
+		t.Fatal("unexpected still failing tests:", n)
+	}
+	if n := names(cmp.NewlySkipped); len(n) != 1 || n[0] != "testHead" {
+		t.Fatal("unexpected newly skipped tests:", n)
+	}
+	if len(cmp.Slower) != 1 || cmp.Slower[0].Name != "pkg.ApiTest.testDelete" || cmp.Slower[0].DurationChange() != 2250*time.Millisecond {
+		t.Fatal("unexpected duration regressions:", cmp.Slower)
+	}
+
+	// A higher threshold hides the slowdown.
+	if cmp = CompareTestResults(result, result, &TestComparisonOptions{MinSlowdown: 5 * time.Second}); len(cmp.Slower) != 0 || len(cmp.StillFailing) != 2 {
+		t.Fatal("unexpected comparison of a report with itself:", cmp)
+	}
+}
+
+func TestExportTestResult(t *testing.T) {
+	var result TestResult
+	if err := json.Unmarshal([]byte(baseTestReport), &result); err != nil {
+		t.Fatal(err)
+	}
+	result.Suites[0].Cases[1].ErrorStackTrace = "expected <201> but was <500>"
+	result.Suites[0].Cases[4].Status = RESULT_STATUS_SKIPPED
+	result.Suites[0].Cases[4].SkippedMessage = "disabled"
+
+	var xmlOut bytes.Buffer
+	if err := result.WriteJUnitXML(&xmlOut); err != nil {
+		t.Fatal("WriteJUnitXML failed:", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(xmlOut.Bytes(), &suites); err != nil {
+		t.Fatal("invalid JUnit XML:", err, xmlOut.String())
+	}
+	suite := suites.Suites[0]
+	if suites.Tests != 5 || suites.Failures != 2 || suites.Skipped != 1 || suites.Time != "3.500" || suite.Name != "pkg.ApiTest" {
+		t.Fatal("unexpected totals:", xmlOut.String())
+	}
+	failed := suite.Cases[1]
+	if failed.ClassName != "pkg.ApiTest" || failed.Name != "testPost" || failed.Time != "0.100" ||
+		failed.Failure == nil || failed.Failure.Message != "expected 201" || failed.Failure.Text != "expected <201> but was <500>" {
+		t.Fatal("unexpected failed test case:", xmlOut.String())
+	}
+	if skipped := suite.Cases[4]; skipped.Skipped == nil || skipped.Skipped.Message != "disabled" || skipped.Failure != nil {
+		t.Fatal("unexpected skipped test case:", xmlOut.String())
+	}
+
+	var jsonOut bytes.Buffer
+	if err := result.WriteJSON(&jsonOut); err != nil {
+		t.Fatal("WriteJSON failed:", err)
+	}
+	var doc testReportJSON
+	json.Unmarshal(jsonOut.Bytes(), &doc)
+	if doc.Version != 1 || doc.Suites[0].Cases[2].Outcome != "failed" || doc.Suites[0].Cases[4].Outcome != "skipped" {
+		t.Fatal("unexpected JSON report:", jsonOut.String())
+	}
+	read, err := ReadTestResultJSON(&jsonOut)
+	if err != nil {
+		t.Fatal("ReadTestResultJSON failed:", err)
+	}
+	if cmp := CompareTestResults(&result, read, nil); len(cmp.StillFailing) != 2 || len(cmp.NewlyFailing)+len(cmp.NewlyPassing)+len(cmp.NewlySkipped) != 0 {
+		t.Fatal("report changed in a round trip:", cmp)
+	}
+	if c := read.Suites[0].Cases[1]; c != result.Suites[0].Cases[1] {
+		t.Fatal("test case changed in a round trip:", c)
+	}
+
+	if _, err := ReadTestResultJSON(bytes.NewBufferString(`{"version":2}`)); err == nil {
+		t.Fatal("read a report of an unknown version")
+	}
+}
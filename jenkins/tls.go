@@ -0,0 +1,68 @@
+package gojenkins
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+)
+
+// TLSOptions are the TLS settings of a Requester, for use with
+// CreateJenkinsWithTLS. See the Requester fields of the same names.
+type TLSOptions struct {
+	CACert     []byte
+	ClientCert []byte
+	ClientKey  []byte
+	ServerName string
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+}
+
+// TLSConfig builds the TLS settings described by CACert, SslVerify,
+// ClientCert/ClientKey and ServerName.
+func (r *Requester) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: !r.SslVerify,
+		ServerName:         r.ServerName,
+	}
+	if !r.SslVerify {
+		r.logger().Warn("TLS certificate verification is disabled", "base", r.Base)
+	}
+
+	if len(r.CACert) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(r.CACert) {
+			return nil, errors.New("no PEM certificates found in CACert")
+		}
+		config.RootCAs = pool
+	}
+
+	if len(r.ClientCert) > 0 || len(r.ClientKey) > 0 {
+		cert, err := tls.X509KeyPair(r.ClientCert, r.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// NewHTTPClient returns an http.Client whose transport uses TLSConfig and
+// which re-applies authentication on redirects to the same host.
+// e.g. client, err := jenkins.Requester.NewHTTPClient(); jenkins.Requester.SetClient(client)
+func (r *Requester) NewHTTPClient() (*http.Client, error) {
+	config, err := r.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return &http.Client{
+		Transport:     transport,
+		CheckRedirect: r.redirectPolicyFunc,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package gojenkins
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTLSTestServer() *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "job"}`))
+	}))
+}
+
+func tlsTestJenkins(t *testing.T, url string, opts *TLSOptions) *Jenkins {
+	j, err := CreateJenkinsWithTLS(url, opts)
+	if err != nil {
+		t.Fatal("CreateJenkinsWithTLS failed:", err)
+	}
+	return j
+}
+
+func TestTLSWithCACert(t *testing.T) {
+	ts := newTLSTestServer()
+	defer ts.Close()
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+
+	j := tlsTestJenkins(t, ts.URL, nil)
+	if _, err := j.GetJob("job"); err == nil {
+		t.Fatal("expected certificate error without CACert")
+	}
+
+	j = tlsTestJenkins(t, ts.URL, &TLSOptions{CACert: caCert})
+	if _, err := j.GetJob("job"); err != nil {
+		t.Fatal("GetJob with CACert failed:", err)
+	}
+
+	// The test certificate is also valid for example.com.
+	j = tlsTestJenkins(t, ts.URL, &TLSOptions{CACert: caCert, ServerName: "example.com"})
+	if _, err := j.GetJob("job"); err != nil {
+		t.Fatal("GetJob with ServerName failed:", err)
+	}
+	j = tlsTestJenkins(t, ts.URL, &TLSOptions{CACert: caCert, ServerName: "jenkins.invalid"})
+	if _, err := j.GetJob("job"); err == nil {
+		t.Fatal("expected certificate error for mismatching ServerName")
+	}
+}
+
+func TestTLSWithoutVerification(t *testing.T) {
+	ts := newTLSTestServer()
+	defer ts.Close()
+
+	j := tlsTestJenkins(t, ts.URL, &TLSOptions{InsecureSkipVerify: true})
+	if _, err := j.GetJob("job"); err != nil {
+		t.Fatal("GetJob with InsecureSkipVerify failed:", err)
+	}
+
+	j = CreateJenkins(nil, ts.URL)
+	if _, err := j.GetJob("job"); err == nil {
+		t.Fatal("CreateJenkins disabled certificate verification")
+	}
+	j.Requester.SslVerify = false
+	client, err := j.Requester.NewHTTPClient()
+	if err != nil {
+		t.Fatal("NewHTTPClient failed:", err)
+	}
+	j.Requester.SetClient(client)
+	if _, err := j.GetJob("job"); err != nil {
+		t.Fatal("GetJob with SslVerify disabled failed:", err)
+	}
+}
+
+func TestTLSClientCertificate(t *testing.T) {
+	ts := newTLSTestServer()
+	defer ts.Close()
+	serverCert := ts.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Requester{
+		ClientCert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Certificate[0]}),
+		ClientKey:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}),
+	}
+	config, err := r.TLSConfig()
+	if err != nil {
+		t.Fatal("TLSConfig failed:", err)
+	}
+	if len(config.Certificates) != 1 {
+		t.Fatal("client certificate was not loaded")
+	}
+
+	r.ClientKey = nil
+	if _, err := r.TLSConfig(); err == nil {
+		t.Fatal("expected error for client certificate without key")
+	}
+}
+
+func TestTLSInvalidCACert(t *testing.T) {
+	r := &Requester{CACert: []byte("not a certificate")}
+	if _, err := r.NewHTTPClient(); err == nil {
+		t.Fatal("expected error for invalid CACert")
+	}
+	if _, err := CreateJenkinsWithTLS("https://jenkins", &TLSOptions{CACert: r.CACert}); err == nil {
+		t.Fatal("expected error for invalid CACert")
+	}
+}
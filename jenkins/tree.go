@@ -0,0 +1,120 @@
+package gojenkins
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tree is a Jenkins tree selector, e.g. Tree("builds[number,result]{0,10}").
+// Passing one to Poll fetches only the selected fields instead of the whole
+// object. More about tree here: https://wiki.jenkins-ci.org/display/JENKINS/Remote+access+API
+type Tree string
+
+// Nesting depth after which TreeOf selects whole objects.
+const maxTreeDepth = 8
+
+// TreeOf derives a tree selector from the json tags of the struct v, so a
+// response decoded into v fetches exactly the fields v declares.
+// Untagged fields are selected by their name with a lower-case first letter.
+// For example
+//
+//	TreeOf(struct {
+//		Name   string `json:"name"`
+//		Builds []struct {
+//			Number int64 `json:"number"`
+//		} `json:"builds"`
+//	}{})
+//
+// returns "name,builds[number]".
+func TreeOf(v interface{}) Tree {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return ""
+	}
+	return Tree(treeFields(t, 0))
+}
+
+func treeFields(t reflect.Type, depth int) string {
+	t = treeElem(t)
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		name := f.Tag.Get("json")
+		if idx := strings.Index(name, ","); idx >= 0 {
+			name = name[:idx]
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" && f.Anonymous && treeElem(f.Type).Kind() == reflect.Struct {
+			if sub := treeFields(f.Type, depth); sub != "" {
+				fields = append(fields, sub)
+			}
+			continue
+		}
+		if name == "" {
+			r, size := utf8.DecodeRuneInString(f.Name)
+			name = string(unicode.ToLower(r)) + f.Name[size:]
+		}
+		if depth < maxTreeDepth {
+			if sub := treeFields(f.Type, depth+1); sub != "" {
+				name += "[" + sub + "]"
+			}
+		}
+		fields = append(fields, name)
+	}
+	return strings.Join(fields, ",")
+}
+
+// treeElem unwraps pointers, slices and arrays down to the element type.
+func treeElem(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// applyTree adds the first Tree in options to query. A tree selects fields
+// explicitly, so any depth parameter is dropped.
+func applyTree(query map[string]string, options []interface{}) map[string]string {
+	for _, o := range options {
+		if tree, ok := o.(Tree); ok && tree != "" {
+			if query == nil {
+				query = map[string]string{}
+			}
+			delete(query, "depth")
+			query["tree"] = string(tree)
+			break
+		}
+	}
+	return query
+}
+
+func (r *Requester) GetJSONTree(endpoint string, responseStruct interface{}, query map[string]string) (*http.Response, error) {
+	return r.GetJSONTreeContext(context.Background(), endpoint, responseStruct, query)
+}
+
+// GetJSONTreeContext is GetJSONContext with the tree parameter set to
+// TreeOf(responseStruct).
+func (r *Requester) GetJSONTreeContext(ctx context.Context, endpoint string, responseStruct interface{}, query map[string]string) (*http.Response, error) {
+	qr := map[string]string{}
+	for k, v := range query {
+		qr[k] = v
+	}
+	qr = applyTree(qr, []interface{}{TreeOf(responseStruct)})
+	return r.GetJSONContext(ctx, endpoint, responseStruct, qr)
+}
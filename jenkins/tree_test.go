@@ -0,0 +1,95 @@
+package gojenkins
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTreeOf(t *testing.T) {
+	type inner struct {
+		Number int64  `json:"number"`
+		URL    string `json:"url,omitempty"`
+	}
+	type embedded struct {
+		Color string `json:"color"`
+	}
+	var v struct {
+		embedded
+		Name    string  `json:"name"`
+		Skipped string  `json:"-"`
+		Builds  []inner `json:"builds"`
+		Last    *inner
+		private string
+	}
+
+	if tree := TreeOf(&v); tree != "color,name,builds[number,url],last[number,url]" {
+		t.Fatal("unexpected tree:", tree)
+	}
+	if tree := TreeOf(nil); tree != "" {
+		t.Fatal("unexpected tree for nil:", tree)
+	}
+}
+
+func TestPollWithTree(t *testing.T) {
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/job/app/api/json":
+			w.Write([]byte(`{"name":"app","builds":[{"number":3}]}`))
+		case "/computer/api/json":
+			w.Write([]byte(`{"computer":[{"displayName":"master","idle":true,"numExecutors":2}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	j := CreateJenkins(nil, ts.URL)
+	job := &Job{Jenkins: j, Raw: new(JobResponse), Base: "/job/app"}
+	if _, err := job.Poll(Tree("name,builds[number]")); err != nil {
+		t.Fatal("Poll failed:", err)
+	}
+	if job.GetName() != "app" || len(job.Raw.Builds) != 1 {
+		t.Fatal("unexpected job:", job.Raw)
+	}
+	if queries[0] != "tree=name%2Cbuilds%5Bnumber%5D" {
+		t.Fatal("unexpected query:", queries[0])
+	}
+
+	statuses, err := j.GetAllNodeStatuses()
+	if err != nil {
+		t.Fatal("GetAllNodeStatuses failed:", err)
+	}
+	if len(statuses) != 1 || !statuses[0].Idle || statuses[0].NumExecutors != 2 {
+		t.Fatal("unexpected statuses:", statuses)
+	}
+	want := "tree=" + "computer%5BdisplayName%2Cidle%2Coffline%2CtemporarilyOffline%2CofflineCauseReason%2CnumExecutors%5D"
+	if queries[1] != want {
+		t.Fatal("unexpected query:", queries[1])
+	}
+}
+
+func TestGetBuildSummaries(t *testing.T) {
+	var tree string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tree = r.URL.Query().Get("tree")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"allBuilds":[{"number":2,"building":true},{"number":1,"result":"SUCCESS"}]}`))
+	}))
+	defer ts.Close()
+
+	job := &Job{Jenkins: CreateJenkins(nil, ts.URL), Raw: new(JobResponse), Base: "/job/app"}
+	builds, err := job.GetBuildSummaries(2)
+	if err != nil {
+		t.Fatal("GetBuildSummaries failed:", err)
+	}
+	if tree != "allBuilds[number,result,building,timestamp,duration,url]{0,2}" {
+		t.Fatal("unexpected tree:", tree)
+	}
+	if len(builds) != 2 || !builds[0].Building || builds[1].Result != "SUCCESS" {
+		t.Fatal("unexpected builds:", builds)
+	}
+}
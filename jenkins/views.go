@@ -1,9 +1,6 @@
 package gojenkins
 
-import (
-	"errors"
-	"strconv"
-)
+import "context"
 
 type View struct {
 	Raw     *ViewResponse
@@ -29,30 +26,32 @@ var (
 
 // Returns True if successfully added Job, otherwise false
 func (v *View) AddJob(name string) (bool, error) {
+	return v.AddJobContext(context.Background(), name)
+}
+
+func (v *View) AddJobContext(ctx context.Context, name string) (bool, error) {
 	url := "/addJobToView"
 	qr := map[string]string{"name": name}
-	resp, err := v.Jenkins.Requester.Post(v.Base+url, nil, nil, qr)
+	_, err := v.Jenkins.Requester.PostContext(ctx, v.Base+url, nil, nil, qr)
 	if err != nil {
 		return false, err
 	}
-	if resp.StatusCode == 200 {
-		return true, nil
-	}
-	return false, errors.New(strconv.Itoa(resp.StatusCode))
+	return true, nil
 }
 
 // Returns True if successfully deleted Job, otherwise false
 func (v *View) DeleteJob(name string) (bool, error) {
+	return v.DeleteJobContext(context.Background(), name)
+}
+
+func (v *View) DeleteJobContext(ctx context.Context, name string) (bool, error) {
 	url := "/removeJobFromView"
 	qr := map[string]string{"name": name}
-	resp, err := v.Jenkins.Requester.Post(v.Base+url, nil, nil, qr)
+	_, err := v.Jenkins.Requester.PostContext(ctx, v.Base+url, nil, nil, qr)
 	if err != nil {
 		return false, err
 	}
-	if resp.StatusCode == 200 {
-		return true, nil
-	}
-	return false, errors.New(strconv.Itoa(resp.StatusCode))
+	return true, nil
 }
 
 func (v *View) GetDescription() string {
@@ -72,7 +71,11 @@ func (v *View) GetUrl() string {
 }
 
 func (v *View) Poll() (int, error) {
-	response, err := v.Jenkins.Requester.GetJSON(v.Base, v.Raw, nil)
+	return v.PollContext(context.Background())
+}
+
+func (v *View) PollContext(ctx context.Context) (int, error) {
+	response, err := v.Jenkins.Requester.GetJSONContext(ctx, v.Base, v.Raw, nil)
 	if err != nil {
 		return 0, err
 	}